@@ -0,0 +1,60 @@
+package hopmap
+
+import "fmt"
+
+// Entry is the node holding a key and its value, which an Allocator hands out to a map.
+// Its fields are only accessed by the map.
+type Entry[K Hashable[K], V any] = entry[K, V]
+
+// Allocator provides the entries of a Map[K, V], see Config.Allocator.
+type Allocator[K Hashable[K], V any] interface {
+	// Alloc returns an entry, whose key and value are then set by the map.
+	Alloc() *Entry[K, V]
+	// Free takes back an entry which the map no longer references, with its key and value cleared.
+	Free(*Entry[K, V])
+}
+
+// allocatorOf returns the allocator of c, which must provide the entries of a Map[K, V] if set.
+func allocatorOf[K Hashable[K], V any](c Config) (Allocator[K, V], error) {
+	alloc, ok := c.Allocator.(Allocator[K, V])
+	if c.Allocator != nil && !ok {
+		return nil, fmt.Errorf("%w: allocator %T does not provide entries of the map type", ErrInvalidConfig, c.Allocator)
+	}
+	return alloc, nil
+}
+
+// derive returns c for a map of other types than the one it configures, which cannot use its allocator.
+func (c Config) derive() Config {
+	c.Allocator = nil
+	return c
+}
+
+// allocEntry returns an empty entry, taken from the allocator if there is one.
+func (m *Map[K, V]) allocEntry() *entry[K, V] {
+	if m.alloc == nil {
+		return new(entry[K, V])
+	}
+	return m.alloc.Alloc()
+}
+
+// newEntry returns an entry holding key and value, stamped with a new generation.
+func (m *Map[K, V]) newEntry(key K, value V) *entry[K, V] {
+	e := m.allocEntry()
+	e.key, e.value, e.gen = key, value, m.stamp()
+	return e
+}
+
+// copyEntry returns a new entry holding the key and value of e, keeping its generation.
+func (m *Map[K, V]) copyEntry(e *entry[K, V]) *entry[K, V] {
+	c := m.allocEntry()
+	*c = *e
+	return c
+}
+
+// freeEntry clears an entry which is no longer referenced by the map, and hands it back to the allocator.
+func (m *Map[K, V]) freeEntry(e *entry[K, V]) {
+	m.resetEntry(e)
+	if m.alloc != nil {
+		m.alloc.Free(e)
+	}
+}
@@ -0,0 +1,107 @@
+package hopmap_test
+
+import (
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+// freeList recycles freed entries, counting the ones it had to allocate.
+type freeList[K hopmap.Hashable[K], V any] struct {
+	free      []*hopmap.Entry[K, V]
+	allocated int
+}
+
+func (l *freeList[K, V]) Alloc() *hopmap.Entry[K, V] {
+	if n := len(l.free); n > 0 {
+		e := l.free[n-1]
+		l.free = l.free[:n-1]
+		return e
+	}
+	l.allocated++
+	return new(hopmap.Entry[K, V])
+}
+
+func (l *freeList[K, V]) Free(e *hopmap.Entry[K, V]) {
+	l.free = append(l.free, e)
+}
+
+func TestAllocator(t *testing.T) {
+	alloc := &freeList[Key, int]{}
+	m := hopmap.New[Key, int](hopmap.Config{Size: 16, BucketSize: 2, AllowOverflow: true, Allocator: alloc})
+
+	for i := 0; i < 20; i++ {
+		require.True(t, m.Put(Key(i), i))
+	}
+	require.Equal(t, 20, alloc.allocated)
+
+	// overwrites keep their entry
+	require.True(t, m.Put(3, 30))
+	require.Equal(t, 20, alloc.allocated)
+
+	// deleting keys, including overflowing ones, frees their entries
+	for i := 0; i < 20; i += 2 {
+		_, ok := m.Delete(Key(i))
+		require.True(t, ok)
+	}
+	require.Len(t, alloc.free, 10)
+
+	for i := 100; i < 110; i++ {
+		require.True(t, m.Put(Key(i), i))
+	}
+	require.Equal(t, 20, alloc.allocated)
+	require.Empty(t, alloc.free)
+
+	for i := 100; i < 110; i++ {
+		v, ok := m.Get(Key(i))
+		require.True(t, ok)
+		require.Equal(t, i, v)
+	}
+	require.NoError(t, m.Validate())
+
+	// a handle does not mistake a recycled entry for its own
+	h := m.Lookup(101)
+	m.Delete(101)
+	require.True(t, m.Put(101+16, 0))
+	_, ok := h.Value()
+	require.False(t, ok)
+
+	m.Clear()
+	require.Len(t, alloc.free, 20)
+}
+
+func TestAllocatorType(t *testing.T) {
+	c := hopmap.Config{Size: 16, BucketSize: 8, Allocator: &freeList[Key, string]{}}
+
+	_, err := hopmap.NewE[Key, int](c)
+	require.ErrorIs(t, err, hopmap.ErrInvalidConfig)
+
+	require.PanicsWithError(t, err.Error(), func() { hopmap.New[Key, int](c) })
+
+	m, err := hopmap.NewE[Key, string](c)
+	require.NoError(t, err)
+	require.True(t, m.Put(1, "one"))
+
+	// maps of other types derived from m do not use its allocator
+	g := hopmap.GroupBy(m, func(k Key, _ string) Key { return k % 2 }, func(a, b string) string { return a + b })
+	require.Equal(t, 1, g.Len())
+}
+
+func TestAllocatorTableFull(t *testing.T) {
+	alloc := &freeList[constKey, int]{}
+	m := hopmap.New[constKey, int](hopmap.Config{Size: 16, BucketSize: 4, Allocator: alloc})
+	for i := 0; i < 4; i++ {
+		require.True(t, m.Put(constKey(i), i))
+	}
+
+	// the entry of a key which cannot be placed goes back to the allocator
+	require.ErrorIs(t, m.TryPut(4, 4), hopmap.ErrTableFull)
+	require.Len(t, alloc.free, 1)
+	require.Equal(t, 5, alloc.allocated)
+
+	_, ok := m.EnsureSlot(5)
+	require.False(t, ok)
+	require.Len(t, alloc.free, 1)
+	require.Equal(t, 5, alloc.allocated)
+}
@@ -0,0 +1,69 @@
+package hopmap
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// BuildFrom creates a map holding the given pairs, sized for a load factor of at most 0.5.
+// Pairs are placed so that as many keys as possible sit in their home slot.
+// If a key is repeated, the last pair wins. It returns ErrTableFull if the pairs
+// cannot be placed at any size.
+func BuildFrom[K Hashable[K], V any](pairs []Pair[K, V]) (*Map[K, V], error) {
+	c := DefaultConfig()
+	c.Size = 1
+	for c.Size < 2*len(pairs) {
+		c.Size <<= 1
+	}
+
+	for {
+		m, key, ok := buildFrom(c, pairs)
+		if ok {
+			return m, nil
+		}
+
+		if uint64(c.Size) >= MaxSize || m.crowdedBy(m.hashKey(key), key) {
+			return nil, fmt.Errorf("%w: unable to place all pairs at any size", ErrTableFull)
+		}
+		c.Size = int(min(2*uint64(c.Size), MaxSize))
+	}
+}
+
+// buildFrom returns the built map, or the key of the first pair it could not place.
+func buildFrom[K Hashable[K], V any](c Config, pairs []Pair[K, V]) (*Map[K, V], K, bool) {
+	m := New[K, V](c)
+
+	sorted := slices.Clone(pairs)
+	slices.SortStableFunc(sorted, func(a, b Pair[K, V]) int {
+		return cmp.Compare(m.hashKey(a.Key), m.hashKey(b.Key))
+	})
+
+	// Insert one pair per home bucket at a time, so that every bucket claims its home slot
+	// before colliding pairs spill over into the slots of the following buckets.
+	type rankedPair struct {
+		Pair[K, V]
+		rank int
+	}
+
+	ranked := make([]rankedPair, len(sorted))
+	for i, p := range sorted {
+		ranked[i] = rankedPair{Pair: p}
+		if i > 0 && m.hashKey(p.Key) == m.hashKey(sorted[i-1].Key) {
+			ranked[i].rank = ranked[i-1].rank + 1
+		}
+	}
+
+	// Duplicate keys share a home bucket, hence the last one is still inserted last.
+	slices.SortStableFunc(ranked, func(a, b rankedPair) int {
+		return cmp.Compare(a.rank, b.rank)
+	})
+
+	for _, p := range ranked {
+		if !m.Put(p.Key, p.Value) {
+			return m, p.Key, false
+		}
+	}
+	var zero K
+	return m, zero, true
+}
@@ -0,0 +1,84 @@
+package hopmap_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+func randomPairs(n int) []hopmap.Pair[Key, uint32] {
+	r := rand.New(rand.NewSource(1))
+
+	pairs := make([]hopmap.Pair[Key, uint32], n)
+	for i := range pairs {
+		pairs[i] = hopmap.Pair[Key, uint32]{Key: Key(r.Uint32()), Value: uint32(i)}
+	}
+	return pairs
+}
+
+func TestBuildFrom(t *testing.T) {
+	pairs := randomPairs(1000)
+	pairs = append(pairs, hopmap.Pair[Key, uint32]{Key: pairs[0].Key, Value: 1000})
+
+	m, err := hopmap.BuildFrom(pairs)
+	require.NoError(t, err)
+	require.Equal(t, 1000, m.Len())
+	require.LessOrEqual(t, m.Load(), 0.5)
+
+	for _, p := range pairs[1:] {
+		v, ok := m.Get(p.Key)
+		require.True(t, ok)
+		require.Equal(t, p.Value, v)
+	}
+
+	naive := hopmap.New[Key, uint32](hopmap.Config{Size: m.Size(), BucketSize: 32})
+	for _, p := range pairs {
+		naive.Put(p.Key, p.Value)
+	}
+	require.LessOrEqual(t, m.HashDistribution().Displaced, naive.HashDistribution().Displaced)
+}
+
+func TestBuildFromCollisions(t *testing.T) {
+	// more keys share a hash code than fit into a neighborhood
+	pairs := make([]hopmap.Pair[constKey, int], 33)
+	for i := range pairs {
+		pairs[i] = hopmap.Pair[constKey, int]{Key: constKey(i), Value: i}
+	}
+
+	_, err := hopmap.BuildFrom(pairs)
+	require.ErrorIs(t, err, hopmap.ErrTableFull)
+
+	m, err := hopmap.BuildFrom(pairs[:32])
+	require.NoError(t, err)
+	require.Equal(t, 32, m.Len())
+}
+
+func benchmarkGetPairs(b *testing.B, m *hopmap.Map[Key, uint32], pairs []hopmap.Pair[Key, uint32]) {
+	// query in an order unrelated to both insertion and placement
+	pairs = append([]hopmap.Pair[Key, uint32](nil), pairs...)
+	rand.New(rand.NewSource(2)).Shuffle(len(pairs), func(i, j int) { pairs[i], pairs[j] = pairs[j], pairs[i] })
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Get(pairs[i%len(pairs)].Key)
+	}
+}
+
+func BenchmarkGetBuildFrom(b *testing.B) {
+	pairs := randomPairs(1 << 16)
+	m, err := hopmap.BuildFrom(pairs)
+	require.NoError(b, err)
+	benchmarkGetPairs(b, m, pairs)
+}
+
+func BenchmarkGetNaiveBuild(b *testing.B) {
+	pairs := randomPairs(1 << 16)
+
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: 1 << 17, BucketSize: 32})
+	for _, p := range pairs {
+		m.Put(p.Key, p.Value)
+	}
+	benchmarkGetPairs(b, m, pairs)
+}
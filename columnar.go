@@ -0,0 +1,68 @@
+package hopmap
+
+import "math/bits"
+
+// ColumnarMap is a hopscotch map storing keys and values in separate arrays,
+// so that scanning values with RangeValues loads neither keys nor entry pointers,
+// which suits analytics aggregating values far more often than they look keys up.
+// Lookups touch both arrays, since keys are compared before their values are read.
+// It uses the same subset of Config as ValueMap.
+type ColumnarMap[K Hashable[K], V any] struct {
+	inlineTable[K, V, columnarSlots[K, V]]
+}
+
+// columnarSlots stores keys and values in separate arrays.
+type columnarSlots[K, V any] struct {
+	keys   []K
+	values []V
+}
+
+var _ GenericMap[hashableInt, int] = (*ColumnarMap[hashableInt, int])(nil)
+
+// NewColumnar creates a ColumnarMap from the given config, rounding Size as New does.
+// It panics if the config is invalid.
+func NewColumnar[K Hashable[K], V any](c Config) *ColumnarMap[K, V] {
+	return &ColumnarMap[K, V]{newInlineTable[K, V, columnarSlots[K, V]](c)}
+}
+
+// RangeValues calls fn for each value of the map, stopping as soon as fn returns false.
+// Unlike Range, it does not read the keys.
+func (m *ColumnarMap[K, V]) RangeValues(fn func(V) bool) {
+	values := m.slots.values
+	for w, word := range m.present {
+		for ; word != 0; word &= word - 1 {
+			if !fn(values[w<<6+bits.TrailingZeros64(word)]) {
+				return
+			}
+		}
+	}
+}
+
+func (columnarSlots[K, V]) alloc(size int) columnarSlots[K, V] {
+	return columnarSlots[K, V]{keys: make([]K, size), values: make([]V, size)}
+}
+
+func (s columnarSlots[K, V]) key(j int) K {
+	return s.keys[j]
+}
+
+func (s columnarSlots[K, V]) value(j int) V {
+	return s.values[j]
+}
+
+func (s columnarSlots[K, V]) set(j int, key K, value V) {
+	s.keys[j], s.values[j] = key, value
+}
+
+func (s columnarSlots[K, V]) setValue(j int, value V) {
+	s.values[j] = value
+}
+
+func (s columnarSlots[K, V]) move(from, to int) {
+	s.keys[to], s.keys[from] = s.keys[from], zeroValue[K]()
+	s.values[to], s.values[from] = s.values[from], zeroValue[V]()
+}
+
+func (s columnarSlots[K, V]) clear(j int) {
+	s.keys[j], s.values[j] = zeroValue[K](), zeroValue[V]()
+}
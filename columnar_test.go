@@ -0,0 +1,39 @@
+package hopmap_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/ostafen/hopmap"
+)
+
+func BenchmarkSumValues(b *testing.B) {
+	const size = 1 << 20
+	c := hopmap.Config{Size: size, BucketSize: 32}
+
+	m := hopmap.New[Key, uint64](c)
+	cm := hopmap.NewColumnar[Key, uint64](c)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < size/2; i++ {
+		k := Key(r.Uint32())
+		m.Put(k, uint64(i))
+		cm.Put(k, uint64(i))
+	}
+
+	var sum uint64
+	add := func(v uint64) bool {
+		sum += v
+		return true
+	}
+
+	b.Run("Map", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			m.RangeValues(add)
+		}
+	})
+	b.Run("ColumnarMap", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			cm.RangeValues(add)
+		}
+	})
+}
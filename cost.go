@@ -0,0 +1,77 @@
+package hopmap
+
+import (
+	"fmt"
+	"math"
+)
+
+// CostConfig configures a CostMap.
+type CostConfig[K, V any] struct {
+	Config
+	// MaxCost bounds the total cost of the entries, beyond which the least recently used ones are evicted.
+	// It must be positive.
+	MaxCost int64
+	// OnEvict, if set, is called with each evicted entry, after it has been removed from the map.
+	OnEvict func(K, V)
+}
+
+// CostMap is a map bounded by the total cost of its entries, rather than by their number,
+// which suits caches of values of different sizes. It evicts the least recently used entries first.
+type CostMap[K Hashable[K], V any] struct {
+	l *LRUMap[K, V]
+}
+
+// NewCost creates a CostMap. It panics if MaxCost is not positive.
+func NewCost[K Hashable[K], V any](c CostConfig[K, V]) *CostMap[K, V] {
+	if c.MaxCost <= 0 {
+		panic(fmt.Errorf("%w: max cost %d must be positive", ErrInvalidConfig, c.MaxCost))
+	}
+
+	l := NewLRU(LRUConfig[K, V]{
+		Config:   c.Config,
+		Capacity: math.MaxInt,
+		OnEvict:  c.OnEvict,
+	})
+	l.maxCost = c.MaxCost
+	return &CostMap[K, V]{l: l}
+}
+
+func (c *CostMap[K, V]) Get(key K) (V, bool) {
+	return c.l.Get(key)
+}
+
+// Put stores value with the given cost, evicting other entries as needed.
+// Entries whose cost alone exceeds MaxCost are rejected, as are those which would exceed it
+// along with the pinned entries. A rejected update leaves the previous value in place.
+func (c *CostMap[K, V]) Put(key K, value V, cost int64) bool {
+	if cost < 0 || cost > c.l.maxCost {
+		return false
+	}
+	return c.l.put(key, value, cost)
+}
+
+func (c *CostMap[K, V]) Delete(key K) (V, bool) {
+	return c.l.Delete(key)
+}
+
+// Pin prevents key from being evicted, reporting whether it is in the map.
+func (c *CostMap[K, V]) Pin(key K) bool {
+	return c.l.Pin(key)
+}
+
+func (c *CostMap[K, V]) Unpin(key K) bool {
+	return c.l.Unpin(key)
+}
+
+func (c *CostMap[K, V]) IsPinned(key K) bool {
+	return c.l.IsPinned(key)
+}
+
+func (c *CostMap[K, V]) Len() int {
+	return c.l.Len()
+}
+
+// CurrentCost returns the total cost of the entries.
+func (c *CostMap[K, V]) CurrentCost() int64 {
+	return c.l.cost
+}
@@ -0,0 +1,83 @@
+package hopmap_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCostMap(t *testing.T) {
+	var evicted []Key
+
+	m := hopmap.NewCost(hopmap.CostConfig[Key, string]{
+		Config:  hopmap.Config{Size: 64, BucketSize: 8},
+		MaxCost: 100,
+		OnEvict: func(k Key, _ string) { evicted = append(evicted, k) },
+	})
+
+	require.True(t, m.Put(1, "a", 40))
+	require.True(t, m.Put(2, "b", 30))
+	require.True(t, m.Put(3, "c", 20))
+	require.Equal(t, int64(90), m.CurrentCost())
+
+	m.Get(1)
+	require.True(t, m.Put(4, "d", 25))
+	require.Equal(t, []Key{2}, evicted)
+	require.Equal(t, int64(85), m.CurrentCost())
+
+	// updating the cost of an entry evicts as well
+	require.True(t, m.Put(4, "d", 60))
+	require.Equal(t, []Key{2, 3}, evicted)
+	require.Equal(t, int64(100), m.CurrentCost())
+	require.Equal(t, 2, m.Len())
+
+	require.False(t, m.Put(5, "e", 101))
+
+	_, ok := m.Delete(4)
+	require.True(t, ok)
+	require.Equal(t, int64(40), m.CurrentCost())
+}
+
+func TestCostMapPin(t *testing.T) {
+	var evicted []Key
+
+	m := hopmap.NewCost(hopmap.CostConfig[Key, string]{
+		Config:  hopmap.Config{Size: 64, BucketSize: 8},
+		MaxCost: 100,
+		OnEvict: func(k Key, _ string) { evicted = append(evicted, k) },
+	})
+
+	require.True(t, m.Put(1, "a", 40))
+	require.True(t, m.Put(2, "b", 30))
+	require.True(t, m.Put(3, "c", 20))
+	require.True(t, m.Pin(1))
+	require.True(t, m.Pin(2))
+
+	// the update only fits by evicting the entry being written
+	require.False(t, m.Put(3, "d", 40))
+	require.Empty(t, evicted)
+	require.Equal(t, int64(90), m.CurrentCost())
+
+	v, ok := m.Get(3)
+	require.True(t, ok)
+	require.Equal(t, "c", v)
+
+	require.False(t, m.Put(4, "e", 40))
+	require.Equal(t, 3, m.Len())
+
+	// evicting the unpinned entry makes room
+	require.True(t, m.Put(4, "e", 30))
+	require.Equal(t, []Key{3}, evicted)
+	require.Equal(t, int64(100), m.CurrentCost())
+}
+
+func TestCostMapInvalidMaxCost(t *testing.T) {
+	for _, maxCost := range []int64{0, -1} {
+		msg := fmt.Sprintf("hopmap: invalid config: max cost %d must be positive", maxCost)
+		require.PanicsWithError(t, msg, func() {
+			hopmap.NewCost(hopmap.CostConfig[Key, int]{Config: hopmap.Config{Size: 16, BucketSize: 8}, MaxCost: maxCost})
+		})
+	}
+}
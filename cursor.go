@@ -0,0 +1,64 @@
+package hopmap
+
+import "slices"
+
+// Cursor iterates over the entries of a Map one at a time, allowing the map to be modified between calls.
+// It walks the table by slot index, which deletions never disturb, since they do not move other entries:
+// deleting keys, visited or not, never makes the cursor skip or repeat a key. Keys inserted meanwhile
+// may or may not be returned, and an insertion which reshifts entries may make the cursor skip or repeat
+// the keys it moves. A rehash, such as a resize, moves every entry: the cursor then stops, and Err
+// reports ErrCursorInvalid.
+type Cursor[K Hashable[K], V any] struct {
+	m        *Map[K, V]
+	rehashes uint64
+	slot     int // next slot to visit
+
+	// overflow holds the entries of the overflow set left to visit, taken once the table has been visited
+	overflow    []*entry[K, V]
+	inOverflow  bool
+	invalidated bool
+}
+
+// Cursor returns a cursor positioned before the first entry of the map.
+func (m *Map[K, V]) Cursor() *Cursor[K, V] {
+	return &Cursor[K, V]{m: m, rehashes: m.rehashes}
+}
+
+// Next returns the next entry, or false once all the entries have been visited or the cursor has been invalidated.
+func (c *Cursor[K, V]) Next() (K, V, bool) {
+	m := c.m
+	if c.rehashes != m.rehashes {
+		c.invalidated = true
+	}
+	if c.invalidated {
+		return zeroValue[K](), zeroValue[V](), false
+	}
+
+	for ; !c.inOverflow && c.slot < m.size; c.slot++ {
+		if e := m.entries[c.slot]; m.isPresent(c.slot) && e != m.tomb {
+			c.slot++
+			return e.key, e.value, true
+		}
+	}
+
+	// deleting from the overflow set moves its last entry, so the entries are checked against a copy
+	if !c.inOverflow {
+		c.overflow, c.inOverflow = slices.Clone(m.overflow), true
+	}
+	for len(c.overflow) > 0 {
+		e := c.overflow[0]
+		c.overflow = c.overflow[1:]
+		if slices.Contains(m.overflow, e) {
+			return e.key, e.value, true
+		}
+	}
+	return zeroValue[K](), zeroValue[V](), false
+}
+
+// Err returns ErrCursorInvalid if the map has been rehashed while the cursor was in use, or nil.
+func (c *Cursor[K, V]) Err() error {
+	if c.invalidated {
+		return ErrCursorInvalid
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package hopmap_test
+
+import (
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorDelete(t *testing.T) {
+	// keys homed in bucket 0 fill it, and the last ones overflow
+	m := hopmap.New[Key, int](hopmap.Config{Size: 64, BucketSize: 4, AllowOverflow: true})
+	for i := 0; i < 40; i++ {
+		require.True(t, m.Put(Key(i), i))
+	}
+	for i := 1; i <= 6; i++ {
+		require.True(t, m.Put(Key(i*64), i))
+	}
+
+	c := m.Cursor()
+	seen := make(map[Key]int)
+	for k, v, ok := c.Next(); ok; k, v, ok = c.Next() {
+		require.Equal(t, int(k)%64+int(k)/64, v)
+		seen[k]++
+
+		// deleting the key just visited, or one still ahead, does not disturb the cursor
+		_, deleted := m.Delete(k)
+		require.True(t, deleted)
+		if k == 10 {
+			m.Delete(30)
+			m.Delete(5 * 64)
+		}
+	}
+	require.NoError(t, c.Err())
+	require.Zero(t, m.Len())
+
+	require.Len(t, seen, 44)
+	for k, n := range seen {
+		require.Equal(t, 1, n, "key %d", k)
+	}
+	require.NotContains(t, seen, Key(30))
+	require.NotContains(t, seen, Key(5*64))
+
+	_, _, ok := c.Next()
+	require.False(t, ok)
+}
+
+func TestCursorInvalidated(t *testing.T) {
+	m := hopmap.New[Key, int](hopmap.Config{Size: 16, BucketSize: 4, AutoResize: true, MaxLoad: 0.75})
+	for i := 0; i < 10; i++ {
+		require.True(t, m.Put(Key(i), i))
+	}
+
+	c := m.Cursor()
+	_, _, ok := c.Next()
+	require.True(t, ok)
+
+	// inserting keys without a resize is tolerated
+	require.True(t, m.Put(10, 10))
+	_, _, ok = c.Next()
+	require.True(t, ok)
+	require.NoError(t, c.Err())
+
+	for i := 11; m.Size() == 16; i++ {
+		require.True(t, m.Put(Key(i), i))
+	}
+	_, _, ok = c.Next()
+	require.False(t, ok)
+	require.ErrorIs(t, c.Err(), hopmap.ErrCursorInvalid)
+
+	// a fresh cursor visits every entry
+	n := 0
+	c = m.Cursor()
+	for _, _, ok := c.Next(); ok; _, _, ok = c.Next() {
+		n++
+	}
+	require.NoError(t, c.Err())
+	require.Equal(t, m.Len(), n)
+}
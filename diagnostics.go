@@ -0,0 +1,354 @@
+package hopmap
+
+import (
+	"fmt"
+	"math/bits"
+	"slices"
+	"unsafe"
+)
+
+// DistReport describes how keys spread across home buckets.
+type DistReport struct {
+	// Mean and Variance of the number of keys per home bucket.
+	Mean, Variance float64
+	// ChiSquared is the Pearson statistic of the bucket occupancy against a uniform distribution.
+	// For a good hash function, it should be close to the number of buckets.
+	ChiSquared float64
+	// Displaced is the fraction of keys not stored in their home slot.
+	Displaced float64
+}
+
+// HashDistribution computes a DistReport from the neighbor bitmaps,
+// which helps spotting poor HashCode implementations.
+// Tombstones and entries of the overflow set are not counted.
+func (m *Map[K, V]) HashDistribution() DistReport {
+	var r DistReport
+
+	counts := make([]int, m.size)
+	n, home := 0, 0
+	for i, nb := range m.neighbors {
+		for off := 0; nb != 0; off, nb = off+1, nb<<1 {
+			if nb&(1<<31) == 0 || m.entries[mod(i+off, m.size)] == m.tomb {
+				continue
+			}
+
+			counts[i]++
+			if off == 0 {
+				home++
+			}
+		}
+		n += counts[i]
+	}
+	if n == 0 {
+		return r
+	}
+
+	r.Mean = float64(n) / float64(m.size)
+	for _, c := range counts {
+		d := float64(c) - r.Mean
+		r.Variance += d * d
+	}
+	r.ChiSquared = r.Variance / r.Mean
+	r.Variance /= float64(m.size)
+	r.Displaced = 1 - float64(home)/float64(n)
+	return r
+}
+
+// OffsetHistogram counts how many entries sit at each offset from their home bucket,
+// offset 0 being the home slot. High counts at large offsets reveal clustering.
+// Entries of the overflow set are not counted.
+func (m *Map[K, V]) OffsetHistogram() [32]int {
+	var h [32]int
+	for i, nb := range m.neighbors {
+		for off := 0; nb != 0; off, nb = off+1, nb<<1 {
+			if nb&(1<<31) != 0 && m.entries[mod(i+off, m.size)] != m.tomb {
+				h[off]++
+			}
+		}
+	}
+	return h
+}
+
+// Clusters returns the groups of at least minSize keys sharing a home bucket, by ascending bucket,
+// which reveals the hash collisions slowing down lookups. Keys of the overflow set are included
+// in the group of their home bucket, after those stored in the table.
+func (m *Map[K, V]) Clusters(minSize int) [][]K {
+	var overflowing map[uint32][]K
+	if len(m.overflow) > 0 {
+		overflowing = make(map[uint32][]K)
+		for _, e := range m.overflow {
+			h := m.hashKey(e.key)
+			overflowing[h] = append(overflowing[h], e.key)
+		}
+	}
+
+	var clusters [][]K
+	for i, nb := range m.neighbors {
+		if bits.OnesCount32(nb)+len(overflowing[uint32(i)]) < max(minSize, 1) {
+			continue
+		}
+
+		var keys []K
+		for j := i; nb != 0; j, nb = j+1, nb<<1 {
+			if e := m.entries[mod(j, m.size)]; nb&(1<<31) != 0 && e != m.tomb {
+				keys = append(keys, e.key)
+			}
+		}
+		// the count above includes tombstones, which are skipped here
+		if keys = append(keys, overflowing[uint32(i)]...); len(keys) >= max(minSize, 1) {
+			clusters = append(clusters, keys)
+		}
+	}
+	return clusters
+}
+
+// WorstProbe returns the key lying farthest from its home bucket, along with that distance,
+// which bounds the number of slots a lookup visits. Ties go to the key of the lowest bucket.
+// It returns false if the table is empty; entries of the overflow set are not considered.
+func (m *Map[K, V]) WorstProbe() (K, int, bool) {
+	var (
+		key   K
+		worst = -1
+	)
+	for i, nb := range m.neighbors {
+		// visit the offsets of bucket i from the farthest one, stopping at the first live entry
+		for ; nb != 0; nb &= nb - 1 {
+			off := 31 - bits.TrailingZeros32(nb)
+			if off <= worst {
+				break
+			}
+
+			if e := m.entries[mod(i+off, m.size)]; e != m.tomb {
+				key, worst = e.key, off
+				break
+			}
+		}
+	}
+	return key, max(worst, 0), worst >= 0
+}
+
+// NeighborsSnapshot returns a copy of the neighbor bitmaps, one per bucket, for offline analysis.
+// The most significant bit of the i-th bitmap is set if slot i holds an entry homed in bucket i,
+// and, in general, bit 31-off is set if slot i+off, modulo Size, holds an entry homed in bucket i.
+// Offsets never reach BucketSize. Tombstones keep the bit of the entry they replaced,
+// and entries of the overflow set are not represented.
+func (m *Map[K, V]) NeighborsSnapshot() []uint32 {
+	return slices.Clone(m.neighbors)
+}
+
+// RegionLoad returns the fraction of slots in [start, end) holding an entry.
+// If start is greater than end, the region wraps around the end of the table,
+// and if they are equal it wraps around the whole table, starting at start.
+// It helps spotting localized clusters that Load averages out.
+func (m *Map[K, V]) RegionLoad(start, end uint32) float64 {
+	if uint64(start) >= uint64(m.size) || uint64(end) > uint64(m.size) {
+		panic(fmt.Sprintf("hopmap: region [%d, %d) out of range for size %d", start, end, m.size))
+	}
+
+	n := int(end) - int(start)
+	if n <= 0 {
+		n += m.size
+	}
+
+	occupied := 0
+	for i := 0; i < n; i++ {
+		if e := m.entries[mod(int(start)+i, m.size)]; e != nil && e != m.tomb {
+			occupied++
+		}
+	}
+	return float64(occupied) / float64(n)
+}
+
+// MemoryBytes estimates the heap footprint of the map, excluding any memory
+// referenced by keys and values themselves.
+func (m *Map[K, V]) MemoryBytes() int {
+	var (
+		e  entry[K, V]
+		p  *entry[K, V]
+		nb uint32
+	)
+	return (len(m.entries)+cap(m.overflow))*int(unsafe.Sizeof(p)) +
+		len(m.neighbors)*int(unsafe.Sizeof(nb)) +
+		len(m.present)*8 +
+		m.n*int(unsafe.Sizeof(e))
+}
+
+// TraceGet returns the slots visited, in order, when looking up key.
+// The last slot holds key if it is present in the map.
+func (m *Map[K, V]) TraceGet(key K) []uint32 {
+	hash := m.hashKey(key)
+
+	var trace []uint32
+	for neighbors, i := m.neighbors[hash], hash; neighbors != 0; neighbors <<= 1 {
+		if neighbors&(1<<31) != 0 {
+			trace = append(trace, i)
+			if e := m.entries[i]; e != m.tomb && e.key.Equals(key) {
+				break
+			}
+		}
+		i = m.nextHash(i)
+	}
+	return trace
+}
+
+// Validate checks that neighbor bitmaps and entries are consistent:
+// every neighbor bit must refer to an entry homed in that bucket, or to a tombstone,
+// and every entry must be referenced by one bit.
+func (m *Map[K, V]) Validate() error {
+	referenced := 0
+	for i, nb := range m.neighbors {
+		for off := 0; nb != 0; off, nb = off+1, nb<<1 {
+			if nb&(1<<31) == 0 {
+				continue
+			}
+
+			if off >= m.config.BucketSize {
+				return fmt.Errorf("%w: bucket %d refers to offset %d beyond bucket size", ErrCorrupted, i, off)
+			}
+
+			j := mod(i+off, m.size)
+			e := m.entries[j]
+			if e == nil {
+				return fmt.Errorf("%w: bucket %d refers to empty slot %d", ErrCorrupted, i, j)
+			}
+			if e == m.tomb {
+				referenced++
+				continue
+			}
+			if home := m.hashKey(e.key); home != uint32(i) {
+				return fmt.Errorf("%w: bucket %d refers to slot %d, homed in bucket %d", ErrCorrupted, i, j, home)
+			}
+			referenced++
+		}
+	}
+
+	live, tombs := 0, 0
+	for j, e := range m.entries {
+		if (e != nil) != m.isPresent(j) {
+			return fmt.Errorf("%w: slot %d disagrees with the present bitmap", ErrCorrupted, j)
+		}
+
+		switch {
+		case e == nil:
+		case e == m.tomb:
+			tombs++
+		default:
+			live++
+		}
+	}
+
+	if referenced != live+tombs {
+		return fmt.Errorf("%w: %d entries and %d tombstones, but %d referenced by neighbor bitmaps", ErrCorrupted, live, tombs, referenced)
+	}
+	if tombs != m.tombs {
+		return fmt.Errorf("%w: %d tombstones, but %d accounted for", ErrCorrupted, tombs, m.tombs)
+	}
+	if live+len(m.overflow) != m.n {
+		return fmt.Errorf("%w: %d entries, %d overflowing, but Len() is %d", ErrCorrupted, live, len(m.overflow), m.n)
+	}
+	return nil
+}
+
+// OrphanedEntries returns the keys stored in the table which lookups cannot reach,
+// since the neighbor bitmap of their home bucket does not refer to their slot.
+// A map without bugs has none; Repair makes them reachable again.
+func (m *Map[K, V]) OrphanedEntries() []K {
+	var orphans []K
+	m.forEachPresent(func(j int) bool {
+		e := m.entries[j]
+		if e == m.tomb {
+			return true
+		}
+
+		home := int(m.hashKey(e.key))
+		if off := mod(j-home, m.size); off >= m.config.BucketSize || m.neighbors[home]&(1<<(31-off)) == 0 {
+			orphans = append(orphans, e.key)
+		}
+		return true
+	})
+	return orphans
+}
+
+// StructurallyEqual reports whether a and b are not only Equal, but also place every entry
+// in the same slot, with identical neighbor bitmaps and overflow sets.
+// It is meant for testing the determinism of placement.
+func StructurallyEqual[K Hashable[K], V any](a, b *Map[K, V], valueEq func(V, V) bool) bool {
+	if a.size != b.size || a.n != b.n || a.tombs != b.tombs || len(a.overflow) != len(b.overflow) ||
+		!slices.Equal(a.neighbors, b.neighbors) || !slices.Equal(a.present, b.present) {
+		return false
+	}
+
+	sameEntry := func(e, f *entry[K, V]) bool {
+		return e.key.Equals(f.key) && valueEq(e.value, f.value)
+	}
+
+	for j, e := range a.entries {
+		f := b.entries[j]
+		switch {
+		case e == nil || f == nil:
+			if e != f {
+				return false
+			}
+		case e == a.tomb || f == b.tomb:
+			if (e == a.tomb) != (f == b.tomb) {
+				return false
+			}
+		case !sameEntry(e, f):
+			return false
+		}
+	}
+
+	for i, e := range a.overflow {
+		if !sameEntry(e, b.overflow[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Repair rebuilds the neighbor bitmaps from the entries, recomputing the home bucket of each one,
+// and returns how many bits have been corrected. Entries lying too far from their home bucket
+// are moved, and tombstones are dropped. It is meant as a recovery for maps failing Validate.
+func (m *Map[K, V]) Repair() int {
+	neighbors := make([]uint32, m.size)
+	clear(m.present)
+
+	var misplaced []*entry[K, V]
+	live := 0
+	for j, e := range m.entries {
+		if e == nil || e == m.tomb {
+			m.entries[j] = nil
+			continue
+		}
+
+		home := m.hashKey(e.key)
+		if off := mod(j-int(home), m.size); off < m.config.BucketSize {
+			neighbors[home] |= 1 << (31 - off)
+			m.markPresent(j)
+			live++
+		} else {
+			misplaced = append(misplaced, e)
+			m.entries[j] = nil
+		}
+	}
+
+	corrected := len(misplaced)
+	for i, nb := range neighbors {
+		corrected += bits.OnesCount32(nb ^ m.neighbors[i])
+	}
+
+	m.neighbors = neighbors
+	m.n = live + len(m.overflow)
+	m.tombs = 0
+
+	for _, e := range misplaced {
+		switch {
+		case m.insert(e):
+		case m.config.AllowOverflow:
+			m.appendOverflow(e)
+		default:
+			m.mustInsert(e)
+		}
+	}
+	return corrected
+}
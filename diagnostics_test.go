@@ -0,0 +1,290 @@
+package hopmap_test
+
+import (
+	"math/rand"
+	"testing"
+	"unsafe"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+// constKey hashes every key to the same bucket.
+type constKey uint32
+
+func (x constKey) Equals(y constKey) bool {
+	return x == y
+}
+
+func (x constKey) HashCode() uint32 {
+	return 0
+}
+
+func TestHashDistribution(t *testing.T) {
+	good := hopmap.New[Key, int](hopmap.Config{Size: 1 << 10, BucketSize: 32})
+	for i := 0; i < 32; i++ {
+		good.Put(Key(i*32), i)
+	}
+
+	bad := hopmap.New[constKey, int](hopmap.Config{Size: 1 << 10, BucketSize: 32})
+	for i := 0; i < 32; i++ {
+		require.True(t, bad.Put(constKey(i), i))
+	}
+
+	g, b := good.HashDistribution(), bad.HashDistribution()
+	require.Equal(t, g.Mean, b.Mean)
+	require.Zero(t, g.Displaced)
+	require.InDelta(t, 31.0/32, b.Displaced, 1e-9)
+	require.Greater(t, b.Variance, 10*g.Variance)
+	require.Greater(t, b.ChiSquared, 10*float64(bad.Size()))
+}
+
+func TestHashDistributionSkipsTombstonesAndOverflow(t *testing.T) {
+	m := hopmap.New[constKey, int](hopmap.Config{
+		Size:          64,
+		BucketSize:    4,
+		UseTombstones: true,
+		AllowOverflow: true,
+	})
+	for i := 0; i < 8; i++ {
+		require.True(t, m.Put(constKey(i), i))
+	}
+	require.Equal(t, 4, m.Stats().Overflow)
+
+	// leave a tombstone in the home slot
+	_, ok := m.Delete(0)
+	require.True(t, ok)
+
+	r := m.HashDistribution()
+	require.InDelta(t, 3.0/64, r.Mean, 1e-9)
+	require.InDelta(t, 1.0, r.Displaced, 1e-9)
+
+	// the three entries left in the table, all homed in bucket 0
+	want := (3-r.Mean)*(3-r.Mean) + 63*r.Mean*r.Mean
+	require.InDelta(t, want/64, r.Variance, 1e-9)
+	require.InDelta(t, want/r.Mean, r.ChiSquared, 1e-9)
+}
+
+func TestHashFinalizer(t *testing.T) {
+	// hash codes differing only above the bits used by the table
+	const size = 1 << 10
+	lowEntropy := func(i int) Key { return Key(i << 10) }
+
+	raw := hopmap.New[Key, int](hopmap.Config{Size: size, BucketSize: 32})
+	for i := 0; i < 32; i++ {
+		require.True(t, raw.Put(lowEntropy(i), i))
+	}
+	require.False(t, raw.Put(lowEntropy(32), 32))
+
+	// DefaultConfig mixes hash codes, and users can plug in their own mixer
+	for _, finalizer := range []func(uint32) uint32{
+		hopmap.DefaultConfig().HashFinalizer,
+		func(h uint32) uint32 { return h ^ h>>10 },
+	} {
+		mixed := hopmap.New[Key, int](hopmap.Config{Size: size, BucketSize: 32, HashFinalizer: finalizer})
+		for i := 0; i < size/2; i++ {
+			require.True(t, mixed.Put(lowEntropy(i), i))
+		}
+
+		r := mixed.HashDistribution()
+		require.Less(t, r.ChiSquared, 1.5*size)
+		require.Less(t, r.Displaced, 0.5)
+	}
+}
+
+func TestOffsetHistogram(t *testing.T) {
+	m := hopmap.New[constKey, int](hopmap.Config{Size: 1 << 10, BucketSize: 32})
+	for i := 0; i < 20; i++ {
+		require.True(t, m.Put(constKey(i), i))
+	}
+
+	h := m.OffsetHistogram()
+	for off, n := range h {
+		if off < 20 {
+			require.Equal(t, 1, n)
+		} else {
+			require.Zero(t, n)
+		}
+	}
+
+	spread := hopmap.New[Key, int](hopmap.Config{Size: 1 << 10, BucketSize: 32})
+	for i := 0; i < 20; i++ {
+		spread.Put(Key(i*32), i)
+	}
+	require.Equal(t, [32]int{20}, spread.OffsetHistogram())
+}
+
+func TestClusters(t *testing.T) {
+	m := hopmap.New[Key, int](hopmap.Config{Size: 64, BucketSize: 4, AllowOverflow: true, UseTombstones: true})
+	for _, k := range []Key{1, 2, 3, 10, 74, 138, 20, 84, 148, 212, 276} {
+		require.True(t, m.Put(k, 0))
+	}
+
+	// bucket 20 holds four keys, and its fifth one overflows
+	require.Equal(t, [][]Key{{10, 74, 138}, {20, 84, 148, 212, 276}}, m.Clusters(2))
+	require.Equal(t, [][]Key{{20, 84, 148, 212, 276}}, m.Clusters(4))
+	require.Len(t, m.Clusters(0), 5)
+
+	// tombstones do not count
+	m.Delete(138)
+	require.Equal(t, [][]Key{{10, 74}, {20, 84, 148, 212, 276}}, m.Clusters(2))
+	m.Delete(74)
+	require.Equal(t, [][]Key{{20, 84, 148, 212, 276}}, m.Clusters(2))
+	require.Empty(t, m.Clusters(6))
+}
+
+func TestWorstProbe(t *testing.T) {
+	m := hopmap.New[Key, int](hopmap.Config{Size: 64, BucketSize: 8, UseTombstones: true})
+	_, _, ok := m.WorstProbe()
+	require.False(t, ok)
+
+	require.True(t, m.Put(30, 0))
+	k, dist, ok := m.WorstProbe()
+	require.True(t, ok)
+	require.Equal(t, Key(30), k)
+	require.Zero(t, dist)
+
+	// keys homed in bucket 10 are pushed past the run of slots [10, 13)
+	for _, k := range []Key{10, 11, 12, 74, 138} {
+		require.True(t, m.Put(k, 0))
+	}
+	k, dist, _ = m.WorstProbe()
+	require.Equal(t, Key(138), k)
+	require.Equal(t, 4, dist)
+
+	// the tombstone left by 138 is skipped
+	m.Delete(138)
+	k, dist, _ = m.WorstProbe()
+	require.Equal(t, Key(74), k)
+	require.Equal(t, 3, dist)
+}
+
+func TestNeighborsSnapshot(t *testing.T) {
+	m := hopmap.New[constKey, int](hopmap.Config{Size: 64, BucketSize: 8})
+	for i := 0; i < 3; i++ {
+		require.True(t, m.Put(constKey(i), i))
+	}
+
+	nb := m.NeighborsSnapshot()
+	require.Len(t, nb, m.Size())
+	require.Equal(t, uint32(0b111<<29), nb[0])
+	for _, b := range nb[1:] {
+		require.Zero(t, b)
+	}
+
+	nb[0] = 0
+	require.Equal(t, uint32(0b111<<29), m.NeighborsSnapshot()[0])
+	require.NoError(t, m.Validate())
+}
+
+func TestRegionLoad(t *testing.T) {
+	m := hopmap.New[Key, int](hopmap.Config{Size: 1 << 10, BucketSize: 32})
+
+	// fill the slots around the end of the table, and a few others
+	for i := 1000; i < 1<<10; i++ {
+		require.True(t, m.Put(Key(i), i))
+	}
+	for i := 0; i < 24; i++ {
+		require.True(t, m.Put(Key(i), i))
+	}
+	for i := 500; i < 510; i++ {
+		require.True(t, m.Put(Key(i), i))
+	}
+
+	require.Equal(t, 1.0, m.RegionLoad(1000, 24))
+	require.Equal(t, 0.5, m.RegionLoad(1000, 72))
+	require.Equal(t, 0.0, m.RegionLoad(100, 200))
+	require.InDelta(t, 0.1, m.RegionLoad(500, 600), 1e-9)
+	require.InDelta(t, m.Load(), m.RegionLoad(0, 1<<10), 1e-9)
+	require.InDelta(t, m.Load(), m.RegionLoad(10, 10), 1e-9)
+	require.InDelta(t, m.Load(), m.RegionLoad(0, 0), 1e-9)
+
+	require.Panics(t, func() { m.RegionLoad(0, 1<<10+1) })
+}
+
+func TestStructurallyEqual(t *testing.T) {
+	build := func() *hopmap.Map[Key, int] {
+		m := hopmap.New[Key, int](hopmap.Config{Size: 1 << 8, BucketSize: 8, Seed: 7, AllowOverflow: true})
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < 250; i++ {
+			m.Put(Key(r.Intn(1<<10)), i)
+		}
+		return m
+	}
+	eq := func(a, b int) bool { return a == b }
+
+	a, b := build(), build()
+	require.True(t, hopmap.StructurallyEqual(a, b, eq))
+	require.True(t, hopmap.Equal(a, b, eq))
+
+	resized := a.Clone()
+	require.NoError(t, resized.Resize(1<<10))
+	require.True(t, hopmap.Equal(a, resized, eq))
+	require.False(t, hopmap.StructurallyEqual(a, resized, eq))
+
+	var k Key
+	a.RangeKeys(func(key Key) bool {
+		k = key
+		return false
+	})
+	b.Put(k, -1)
+	require.False(t, hopmap.Equal(a, b, eq))
+	require.False(t, hopmap.StructurallyEqual(a, b, eq))
+}
+
+func TestMemoryBytes(t *testing.T) {
+	small := hopmap.New[Key, uint64](hopmap.Config{Size: 1 << 10, BucketSize: 32})
+	large := hopmap.New[Key, uint64](hopmap.Config{Size: 1 << 12, BucketSize: 32})
+	require.Equal(t, 4*small.MemoryBytes(), large.MemoryBytes())
+
+	empty := small.MemoryBytes()
+	for i := 0; i < 100; i++ {
+		small.Put(Key(i), uint64(i))
+	}
+	perEntry := (small.MemoryBytes() - empty) / 100
+	require.Equal(t, int(unsafe.Sizeof(hopmap.Entry[Key, uint64]{})), perEntry)
+}
+
+func TestTraceGet(t *testing.T) {
+	m := hopmap.New[Key, int](hopmap.Config{Size: 16, BucketSize: 8})
+
+	// keys 1, 17, 33 share home bucket 1, while 2 sits between them
+	m.Put(1, 0)
+	m.Put(2, 0)
+	m.Put(17, 0)
+	m.Put(33, 0)
+
+	require.Equal(t, []uint32{1}, m.TraceGet(1))
+	require.Equal(t, []uint32{1, 3}, m.TraceGet(17))
+	require.Equal(t, []uint32{1, 3, 4}, m.TraceGet(33))
+	require.Equal(t, []uint32{1, 3, 4}, m.TraceGet(49))
+	require.Equal(t, []uint32{2}, m.TraceGet(2))
+	require.Empty(t, m.TraceGet(5))
+}
+
+// boxedKey hashes to the value it points to, so that tests can change its hash code after insertion.
+type boxedKey struct {
+	h *uint32
+}
+
+func (x boxedKey) Equals(y boxedKey) bool {
+	return x.h == y.h
+}
+
+func (x boxedKey) HashCode() uint32 {
+	return *x.h
+}
+
+func TestValidate(t *testing.T) {
+	m := hopmap.New[boxedKey, int](hopmap.Config{Size: 64, BucketSize: 8})
+	keys := make([]uint32, 40)
+	for i := range keys {
+		keys[i] = uint32(i * 3)
+		require.True(t, m.Put(boxedKey{&keys[i]}, i))
+	}
+	require.NoError(t, m.Validate())
+
+	// the entry of the key is now referenced by a bucket it is not homed in
+	keys[7]++
+	require.ErrorIs(t, m.Validate(), hopmap.ErrCorrupted)
+}
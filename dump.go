@@ -0,0 +1,131 @@
+package hopmap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Encoder encodes the keys and values of a map into bytes.
+type Encoder[K, V any] struct {
+	Key   func(K) []byte
+	Value func(V) []byte
+}
+
+// Decoder decodes the keys and values encoded by an Encoder.
+// The bytes passed to its functions are not reused, so they may be retained,
+// though they keep the whole dump in memory.
+type Decoder[K, V any] struct {
+	Key   func([]byte) (K, error)
+	Value func([]byte) (V, error)
+}
+
+// DumpTo writes the entries of the map to the file at path in the format of WriteMmap,
+// to be loaded back with LoadFrom, or queried in place with OpenMmap.
+// The entries are written to a temporary file of the same directory, which is then renamed to path,
+// so that path holds either its previous content or the complete dump, even if the process crashes.
+// The directory is synced after the rename, so that the new dump also survives a system crash.
+func (m *Map[K, V]) DumpTo(path string, enc Encoder[K, V]) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	w := bufio.NewWriter(f)
+	if _, err = WriteMmap(w, m, enc.Key, enc.Value); err != nil {
+		return err
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if err = f.Chmod(0o644); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+// syncDir flushes the directory entries of dir to stable storage.
+// Windows does not support syncing directories, and persists renames on its own.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	if err := d.Sync(); err != nil {
+		d.Close()
+		return err
+	}
+	return d.Close()
+}
+
+// LoadFrom rebuilds a map configured by c from the file written by DumpTo at path.
+// Its checksum is verified before any record is decoded, and a damaged file is reported as ErrCorrupted.
+// The dump does not record the configuration of the dumped map, so c should match it:
+// e.g. entries which only fitted thanks to AllowOverflow make LoadFrom fail with ErrTableFull without it.
+func LoadFrom[K Hashable[K], V any](path string, c Config, dec Decoder[K, V]) (*Map[K, V], error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyMmap(data); err != nil {
+		return nil, err
+	}
+	mm, err := OpenMmap(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := NewE[K, V](c)
+	if err != nil {
+		return nil, err
+	}
+
+	i := 0
+	mm.Range(func(kb, vb []byte) bool {
+		var k K
+		var v V
+		if k, err = dec.Key(kb); err != nil {
+			err = fmt.Errorf("hopmap: decoding key of record %d: %w", i, err)
+			return false
+		}
+		if v, err = dec.Value(vb); err != nil {
+			err = fmt.Errorf("hopmap: decoding value of record %d: %w", i, err)
+			return false
+		}
+		if err = m.PutUnique(k, v); err != nil {
+			if errors.Is(err, ErrDuplicateKey) {
+				err = fmt.Errorf("%w: %w", ErrCorrupted, err)
+			}
+			err = fmt.Errorf("hopmap: loading record %d: %w", i, err)
+			return false
+		}
+		i++
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,136 @@
+package hopmap_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	dumpEncoder = hopmap.Encoder[Key, string]{Key: encodeKey, Value: encodeValue}
+	dumpDecoder = hopmap.Decoder[Key, string]{
+		Key: func(b []byte) (Key, error) {
+			if len(b) != 4 {
+				return 0, errors.New("key must be 4 bytes long")
+			}
+			return Key(binary.LittleEndian.Uint32(b)), nil
+		},
+		Value: func(b []byte) (string, error) { return string(b), nil },
+	}
+)
+
+func TestDumpTo(t *testing.T) {
+	c := hopmap.Config{Size: 256, BucketSize: 8, UseTombstones: true, AllowOverflow: true, Seed: 42}
+	m := hopmap.New[Key, string](c)
+	for i := 0; i < 200; i++ {
+		require.True(t, m.Put(Key(i*3), strconv.Itoa(i)))
+	}
+	for i := 0; i < 50; i++ {
+		m.Delete(Key(i * 3))
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "index")
+	require.NoError(t, os.WriteFile(path, []byte("stale"), 0o644))
+	require.NoError(t, m.DumpTo(path, dumpEncoder))
+
+	// the previous file is replaced, and no temporary file is left behind
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	loaded, err := hopmap.LoadFrom(path, c, dumpDecoder)
+	require.NoError(t, err)
+	require.NoError(t, loaded.Validate())
+	require.Equal(t, m.Size(), loaded.Size())
+	require.Equal(t, c, loaded.Config())
+	require.True(t, hopmap.Equal(m, loaded, func(a, b string) bool { return a == b }))
+
+	// the dump can also be queried in place
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	mm, err := hopmap.OpenMmap(data)
+	require.NoError(t, err)
+	v, ok := mm.Get(encodeKey(300))
+	require.True(t, ok)
+	require.Equal(t, "100", string(v))
+
+	empty := hopmap.New[Key, string](hopmap.Config{Size: 16, BucketSize: 4})
+	require.NoError(t, empty.DumpTo(path, dumpEncoder))
+	loaded, err = hopmap.LoadFrom(path, hopmap.Config{Size: 16, BucketSize: 4}, dumpDecoder)
+	require.NoError(t, err)
+	require.Zero(t, loaded.Len())
+}
+
+func TestDumpToOverflow(t *testing.T) {
+	// colliding keys only fit thanks to AllowOverflow, which the loaded map must keep
+	c := hopmap.Config{Size: 16, BucketSize: 4, AllowOverflow: true}
+	m := hopmap.New[constKey, string](c)
+	for i := 0; i < 8; i++ {
+		require.True(t, m.Put(constKey(i), strconv.Itoa(i)))
+	}
+
+	path := filepath.Join(t.TempDir(), "index")
+	require.NoError(t, m.DumpTo(path, hopmap.Encoder[constKey, string]{
+		Key:   func(k constKey) []byte { return encodeKey(Key(k)) },
+		Value: encodeValue,
+	}))
+
+	dec := hopmap.Decoder[constKey, string]{
+		Key: func(b []byte) (constKey, error) {
+			k, err := dumpDecoder.Key(b)
+			return constKey(k), err
+		},
+		Value: dumpDecoder.Value,
+	}
+	loaded, err := hopmap.LoadFrom(path, c, dec)
+	require.NoError(t, err)
+	require.True(t, hopmap.Equal(m, loaded, func(a, b string) bool { return a == b }))
+
+	c.AllowOverflow = false
+	_, err = hopmap.LoadFrom(path, c, dec)
+	require.ErrorIs(t, err, hopmap.ErrTableFull)
+}
+
+func TestLoadFromCorrupted(t *testing.T) {
+	c := hopmap.Config{Size: 64, BucketSize: 8}
+	m := hopmap.New[Key, string](c)
+	for i := 0; i < 20; i++ {
+		require.True(t, m.Put(Key(i), strconv.Itoa(i)))
+	}
+
+	path := filepath.Join(t.TempDir(), "index")
+	require.NoError(t, m.DumpTo(path, dumpEncoder))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	load := func(data []byte) error {
+		require.NoError(t, os.WriteFile(path, data, 0o644))
+		_, err := hopmap.LoadFrom(path, c, dumpDecoder)
+		return err
+	}
+
+	flipped := append([]byte(nil), data...)
+	flipped[len(data)/2] ^= 1
+	require.ErrorIs(t, load(flipped), hopmap.ErrCorrupted)
+	require.ErrorIs(t, load(data[:len(data)-1]), hopmap.ErrCorrupted)
+	require.ErrorIs(t, load(data[:10]), hopmap.ErrCorrupted)
+	require.NoError(t, load(data))
+
+	// decoding errors are reported as such
+	_, err = hopmap.LoadFrom(path, c, hopmap.Decoder[Key, string]{
+		Key:   dumpDecoder.Key,
+		Value: func([]byte) (string, error) { return "", strconv.ErrSyntax },
+	})
+	require.ErrorIs(t, err, strconv.ErrSyntax)
+	require.NotErrorIs(t, err, hopmap.ErrCorrupted)
+
+	_, err = hopmap.LoadFrom(filepath.Join(t.TempDir(), "missing"), c, dumpDecoder)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,41 @@
+package hopmap
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrTableFull      = errors.New("hopmap: table full")
+	ErrInvalidConfig  = errors.New("hopmap: invalid config")
+	ErrResizeTooSmall = errors.New("hopmap: resize too small")
+	ErrCorrupted      = errors.New("hopmap: corrupted table")
+	ErrDuplicateKey   = errors.New("hopmap: duplicate key")
+	ErrCursorInvalid  = errors.New("hopmap: cursor invalidated by a rehash")
+)
+
+func (c Config) validate() error {
+	if c.Size <= 0 || uint64(c.Size) > MaxSize {
+		return fmt.Errorf("%w: size %d must be in [1, %d]", ErrInvalidConfig, c.Size, uint64(MaxSize))
+	}
+	if c.BucketSize <= 0 || c.BucketSize > 32 {
+		return fmt.Errorf("%w: bucket size %d must be in [1, 32]", ErrInvalidConfig, c.BucketSize)
+	}
+	if c.AutoResize && c.BucketSize < MinAutoResizeBucketSize {
+		return fmt.Errorf("%w: bucket size %d must be at least %d with AutoResize",
+			ErrInvalidConfig, c.BucketSize, MinAutoResizeBucketSize)
+	}
+	if !(c.MaxLoad >= 0 && c.MaxLoad <= 1) {
+		return fmt.Errorf("%w: max load %v must be in [0, 1]", ErrInvalidConfig, c.MaxLoad)
+	}
+	if !(c.MinLoad >= 0 && c.MinLoad < 1) || (c.MinLoad > 0 && c.MaxLoad > 0 && c.MinLoad >= c.MaxLoad) {
+		return fmt.Errorf("%w: min load %v must be in [0, 1) and below max load %v", ErrInvalidConfig, c.MinLoad, c.MaxLoad)
+	}
+	if c.OverflowCapacity < 0 {
+		return fmt.Errorf("%w: overflow capacity %d must not be negative", ErrInvalidConfig, c.OverflowCapacity)
+	}
+	if c.OverflowGrowth != 0 && !(c.OverflowGrowth > 1) {
+		return fmt.Errorf("%w: overflow growth %v must be greater than 1", ErrInvalidConfig, c.OverflowGrowth)
+	}
+	return nil
+}
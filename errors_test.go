@@ -0,0 +1,156 @@
+package hopmap_test
+
+import (
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrors(t *testing.T) {
+	_, err := hopmap.NewE[Key, uint32](hopmap.Config{Size: 16, BucketSize: 64})
+	require.ErrorIs(t, err, hopmap.ErrInvalidConfig)
+
+	_, err = hopmap.NewE[Key, uint32](hopmap.Config{Size: 16, BucketSize: 8, MinLoad: 0.8, MaxLoad: 0.5})
+	require.ErrorIs(t, err, hopmap.ErrInvalidConfig)
+
+	m, err := hopmap.NewE[Key, uint32](hopmap.Config{Size: 4, BucketSize: 4})
+	require.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, m.TryPut(Key(i), uint32(i)))
+	}
+	require.ErrorIs(t, m.TryPut(4, 4), hopmap.ErrTableFull)
+
+	require.ErrorIs(t, m.Resize(2), hopmap.ErrResizeTooSmall)
+	require.ErrorIs(t, m.Resize(0), hopmap.ErrInvalidConfig)
+
+	require.NoError(t, m.Resize(8))
+	require.Equal(t, 8, m.Size())
+	require.NoError(t, m.TryPut(4, 4))
+}
+
+func TestPutUnique(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: 16, BucketSize: 4, Stats: true})
+
+	// all keys share home bucket 0
+	require.NoError(t, m.PutUnique(16, 10))
+	require.ErrorIs(t, m.PutUnique(16, 20), hopmap.ErrDuplicateKey)
+
+	v, _ := m.Get(16)
+	require.Equal(t, uint32(10), v)
+	require.Equal(t, 1, m.Len())
+	require.Equal(t, uint64(1), m.Stats().Failures)
+
+	for i := 2; i < 5; i++ {
+		require.NoError(t, m.PutUnique(Key(16*i), uint32(i)))
+	}
+	require.ErrorIs(t, m.PutUnique(16*5, 5), hopmap.ErrTableFull)
+}
+
+func TestOnDuplicate(t *testing.T) {
+	for policy, want := range map[hopmap.DuplicatePolicy]uint32{
+		hopmap.Overwrite:       20,
+		hopmap.KeepExisting:    10,
+		hopmap.FailOnDuplicate: 10,
+	} {
+		m := hopmap.New[Key, uint32](hopmap.Config{Size: 16, BucketSize: 4, OnDuplicate: policy})
+		require.NoError(t, m.TryPut(1, 10))
+
+		err := m.TryPut(1, 20)
+		if policy == hopmap.FailOnDuplicate {
+			require.ErrorIs(t, err, hopmap.ErrDuplicateKey)
+			require.False(t, m.Put(1, 20))
+		} else {
+			require.NoError(t, err)
+			require.True(t, m.Put(1, 20))
+		}
+
+		v, _ := m.Get(1)
+		require.Equal(t, want, v)
+		require.Equal(t, 1, m.Len())
+	}
+}
+
+func TestOnDuplicateIngest(t *testing.T) {
+	pairs := []hopmap.Pair[Key, uint32]{{Key: 2, Value: 2}, {Key: 1, Value: 20}, {Key: 3, Value: 3}}
+
+	for policy, want := range map[hopmap.DuplicatePolicy]uint32{
+		hopmap.Overwrite:       20,
+		hopmap.KeepExisting:    10,
+		hopmap.FailOnDuplicate: 10,
+	} {
+		m := hopmap.New[Key, uint32](hopmap.Config{Size: 16, BucketSize: 4, OnDuplicate: policy})
+		m.Put(1, 10)
+
+		// a rejected duplicate is dropped, and the following pairs are still stored
+		ch := make(chan hopmap.Pair[Key, uint32], len(pairs))
+		for _, p := range pairs {
+			ch <- p
+		}
+		close(ch)
+		require.Equal(t, 2, m.Ingest(ch, true))
+		require.Equal(t, 3, m.Len())
+
+		v, _ := m.Get(1)
+		require.Equal(t, want, v)
+	}
+}
+
+func TestOnDuplicatePutUntilLoad(t *testing.T) {
+	pairs := []hopmap.Pair[Key, uint32]{{Key: 2, Value: 2}, {Key: 1, Value: 20}, {Key: 3, Value: 3}}
+
+	for policy, want := range map[hopmap.DuplicatePolicy]uint32{
+		hopmap.Overwrite:       20,
+		hopmap.KeepExisting:    10,
+		hopmap.FailOnDuplicate: 10,
+	} {
+		m := hopmap.New[Key, uint32](hopmap.Config{Size: 16, BucketSize: 4, OnDuplicate: policy})
+		m.Put(1, 10)
+
+		// a rejected duplicate stops the batch, leaving the following pairs to the caller
+		n := m.PutUntilLoad(pairs, 1)
+		if policy == hopmap.FailOnDuplicate {
+			require.Equal(t, 1, n)
+			require.Equal(t, 2, m.Len())
+		} else {
+			require.Equal(t, 3, n)
+			require.Equal(t, 3, m.Len())
+		}
+
+		v, _ := m.Get(1)
+		require.Equal(t, want, v)
+	}
+}
+
+func TestOnDuplicateGetOrCompute(t *testing.T) {
+	for policy, want := range map[hopmap.DuplicatePolicy]uint32{
+		hopmap.Overwrite:       20,
+		hopmap.KeepExisting:    10,
+		hopmap.FailOnDuplicate: 10,
+	} {
+		m := hopmap.New[Key, uint32](hopmap.Config{Size: 16, BucketSize: 4, OnDuplicate: policy})
+
+		// the factory inserts the key being computed
+		v, err := m.GetOrCompute(1, func(k Key) (uint32, error) {
+			m.Put(k, 10)
+			return 20, nil
+		})
+		if policy == hopmap.FailOnDuplicate {
+			require.ErrorIs(t, err, hopmap.ErrDuplicateKey)
+		} else {
+			require.NoError(t, err)
+			require.Equal(t, want, v)
+		}
+
+		require.Equal(t, 1, m.Len())
+		require.NoError(t, m.Validate())
+		v, _ = m.Get(1)
+		require.Equal(t, want, v)
+	}
+}
+
+func TestMaxSize(t *testing.T) {
+	_, err := hopmap.NewE[Key, uint32](hopmap.Config{Size: hopmap.MaxSize + 1, BucketSize: 32})
+	require.ErrorIs(t, err, hopmap.ErrInvalidConfig)
+}
@@ -0,0 +1,69 @@
+package hopmap
+
+// FIFOConfig configures a FIFOMap.
+type FIFOConfig[K, V any] struct {
+	Config
+	// Capacity is the maximum number of entries, beyond which the oldest inserted one is evicted.
+	Capacity int
+	// OnEvict, if set, is called with each evicted entry, after it has been removed from the map.
+	OnEvict func(K, V)
+}
+
+// FIFOMap is a map bounded to a fixed number of entries, which evicts the oldest inserted entry
+// when full. Unlike LRUMap, neither Get nor overwriting a key affect the eviction order,
+// which suits sliding windows, e.g. for deduplication.
+type FIFOMap[K Hashable[K], V any] struct {
+	l *LRUMap[K, V]
+}
+
+var _ GenericMap[hashableInt, int] = (*FIFOMap[hashableInt, int])(nil)
+
+// NewFIFO creates a FIFOMap. It panics if Capacity is not positive.
+func NewFIFO[K Hashable[K], V any](c FIFOConfig[K, V]) *FIFOMap[K, V] {
+	l := NewLRU(LRUConfig[K, V]{
+		Config:   c.Config,
+		Capacity: c.Capacity,
+		OnEvict:  c.OnEvict,
+	})
+	l.insertionOrder = true
+	return &FIFOMap[K, V]{l: l}
+}
+
+func (f *FIFOMap[K, V]) Get(key K) (V, bool) {
+	return f.l.Get(key)
+}
+
+// Put stores value under key. A key which is already in the map keeps its place in the eviction order.
+func (f *FIFOMap[K, V]) Put(key K, value V) bool {
+	return f.l.Put(key, value)
+}
+
+func (f *FIFOMap[K, V]) Delete(key K) (V, bool) {
+	return f.l.Delete(key)
+}
+
+// Pin prevents key from being evicted, reporting whether it is in the map.
+func (f *FIFOMap[K, V]) Pin(key K) bool {
+	return f.l.Pin(key)
+}
+
+func (f *FIFOMap[K, V]) Unpin(key K) bool {
+	return f.l.Unpin(key)
+}
+
+func (f *FIFOMap[K, V]) IsPinned(key K) bool {
+	return f.l.IsPinned(key)
+}
+
+func (f *FIFOMap[K, V]) Len() int {
+	return f.l.Len()
+}
+
+// Range calls fn for each entry, from the oldest inserted one, stopping as soon as fn returns false.
+func (f *FIFOMap[K, V]) Range(fn func(K, V) bool) {
+	for node := f.l.root.prev; node != &f.l.root; node = node.prev {
+		if !fn(node.key, node.value) {
+			return
+		}
+	}
+}
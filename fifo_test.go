@@ -0,0 +1,103 @@
+package hopmap_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFIFOEviction(t *testing.T) {
+	var evicted []Key
+	m := hopmap.NewFIFO(hopmap.FIFOConfig[Key, int]{
+		Config:   hopmap.Config{Size: 16, BucketSize: 8},
+		Capacity: 3,
+		OnEvict: func(k Key, v int) {
+			require.Equal(t, int(k), v)
+			evicted = append(evicted, k)
+		},
+	})
+
+	for i := 1; i <= 3; i++ {
+		require.True(t, m.Put(Key(i), i))
+	}
+
+	// neither reads nor overwrites save the oldest key
+	m.Get(1)
+	m.Put(1, 1)
+	require.True(t, m.Put(4, 4))
+	require.Equal(t, []Key{1}, evicted)
+
+	_, ok := m.Get(1)
+	require.False(t, ok)
+	require.Equal(t, 3, m.Len())
+
+	// a deleted key leaves the queue, and is reinserted as the newest one
+	v, ok := m.Delete(2)
+	require.True(t, ok)
+	require.Equal(t, 2, v)
+	require.True(t, m.Put(2, 2))
+	require.True(t, m.Put(5, 5))
+	require.Equal(t, []Key{1, 3}, evicted)
+
+	var order []Key
+	m.Range(func(k Key, _ int) bool {
+		order = append(order, k)
+		return true
+	})
+	require.Equal(t, []Key{4, 2, 5}, order)
+}
+
+func TestFIFOFullTable(t *testing.T) {
+	// the table holds exactly Capacity entries, so the oldest one must go before a new one fits
+	m := hopmap.NewFIFO(hopmap.FIFOConfig[Key, int]{
+		Config:   hopmap.Config{Size: 4, BucketSize: 4},
+		Capacity: 4,
+	})
+
+	for i := 0; i < 16; i++ {
+		require.True(t, m.Put(Key(i), i))
+		require.LessOrEqual(t, m.Len(), 4)
+	}
+
+	var order []Key
+	m.Range(func(k Key, _ int) bool {
+		order = append(order, k)
+		return true
+	})
+	require.Equal(t, []Key{12, 13, 14, 15}, order)
+}
+
+func TestFIFOPin(t *testing.T) {
+	var evicted []Key
+	m := hopmap.NewFIFO(hopmap.FIFOConfig[Key, int]{
+		Config:   hopmap.Config{Size: 16, BucketSize: 8},
+		Capacity: 3,
+		OnEvict:  func(k Key, v int) { evicted = append(evicted, k) },
+	})
+
+	m.Put(1, 1)
+	require.True(t, m.Pin(1))
+	require.False(t, m.Pin(42))
+
+	for i := 2; i < 6; i++ {
+		m.Put(Key(i), i)
+	}
+	require.Equal(t, []Key{2, 3}, evicted)
+	require.True(t, m.IsPinned(1))
+
+	// once unpinned, the key is the oldest one again
+	require.True(t, m.Unpin(1))
+	m.Put(6, 6)
+	require.Equal(t, []Key{2, 3, 1}, evicted)
+}
+
+func TestFIFOInvalidCapacity(t *testing.T) {
+	for _, capacity := range []int{0, -1} {
+		msg := fmt.Sprintf("hopmap: invalid config: capacity %d must be positive", capacity)
+		require.PanicsWithError(t, msg, func() {
+			hopmap.NewFIFO(hopmap.FIFOConfig[Key, int]{Config: hopmap.Config{Size: 16, BucketSize: 8}, Capacity: capacity})
+		})
+	}
+}
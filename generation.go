@@ -0,0 +1,26 @@
+package hopmap
+
+// stamp returns a new generation, for an entry being inserted or updated.
+func (m *Map[_, _]) stamp() uint64 {
+	m.gen++
+	return m.gen
+}
+
+// Generation returns a marker of the current state of the map, for RangeSince.
+// It grows with each insertion or update, starting from zero for an empty map.
+func (m *Map[_, _]) Generation() uint64 {
+	return m.gen
+}
+
+// RangeSince is like Range, but only visits the entries inserted or updated after
+// the given generation was returned by Generation, so that changes can be processed incrementally.
+// Deletions are not reported, and neither are updates made through pointers returned by GetPointer.
+func (m *Map[K, V]) RangeSince(gen uint64, fn func(K, V) bool) {
+	if gen >= m.gen {
+		return
+	}
+
+	m.forEach(func(e *entry[K, V]) bool {
+		return e.gen <= gen || fn(e.key, e.value)
+	})
+}
@@ -0,0 +1,56 @@
+package hopmap_test
+
+import (
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRangeSince(t *testing.T) {
+	m := hopmap.New[Key, int](hopmap.Config{Size: 64, BucketSize: 8, AutoResize: true, MaxLoad: 0.75})
+	require.Zero(t, m.Generation())
+
+	for i := 0; i < 40; i++ {
+		m.Put(Key(i), i)
+	}
+	gen := m.Generation()
+	require.Equal(t, uint64(40), gen)
+
+	since := func(gen uint64) map[Key]int {
+		changed := make(map[Key]int)
+		m.RangeSince(gen, func(k Key, v int) bool {
+			changed[k] = v
+			return true
+		})
+		return changed
+	}
+	require.Empty(t, since(gen))
+	require.Len(t, since(0), 40)
+
+	// insertions and updates are reported, deletions are not
+	m.Put(3, 30)
+	m.Put(100, 100)
+	m.UpdateWhere(func(k Key, _ int) bool { return k == 7 }, func(v int) int { return -v })
+	m.Delete(5)
+	h := m.Lookup(9)
+	h.Set(90)
+
+	changed := map[Key]int{3: 30, 100: 100, 7: -7, 9: 90}
+	require.Equal(t, changed, since(gen))
+
+	// generations survive resizes and clones
+	require.NoError(t, m.Resize(256))
+	require.Equal(t, changed, since(gen))
+
+	c := m.Clone()
+	require.Equal(t, m.Generation(), c.Generation())
+	c.Put(11, 110)
+	count := 0
+	c.RangeSince(gen, func(Key, int) bool {
+		count++
+		return true
+	})
+	require.Equal(t, 5, count)
+	require.Equal(t, changed, since(gen))
+}
@@ -0,0 +1,216 @@
+package hopmap_test
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenericMap(t *testing.T) {
+	c := hopmap.Config{Size: 1 << 10, BucketSize: 32}
+
+	impls := map[string]hopmap.GenericMap[Key, int]{
+		"Map":          hopmap.New[Key, int](c),
+		"SyncMap":      hopmap.NewSync[Key, int](c),
+		"ShardedMap":   hopmap.NewSharded[Key, int](4, c),
+		"RCUMap":       hopmap.NewRCU[Key, int](c),
+		"VersionedMap": hopmap.NewVersioned[Key, int](2, c),
+		"FIFOMap":      hopmap.NewFIFO(hopmap.FIFOConfig[Key, int]{Config: c, Capacity: 1 << 10}),
+		"ColumnarMap":  hopmap.NewColumnar[Key, int](c),
+	}
+
+	for name, m := range impls {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				require.True(t, m.Put(Key(i), i))
+			}
+			require.Equal(t, 100, m.Len())
+
+			v, ok := m.Delete(50)
+			require.True(t, ok)
+			require.Equal(t, 50, v)
+
+			_, ok = m.Get(50)
+			require.False(t, ok)
+
+			sum := 0
+			m.Range(func(k Key, v int) bool {
+				sum += v
+				return true
+			})
+			require.Equal(t, 4950-50, sum)
+		})
+	}
+}
+
+func TestConcurrentMaps(t *testing.T) {
+	c := hopmap.Config{Size: 1 << 12, BucketSize: 32, Stats: true}
+
+	for name, m := range map[string]hopmap.GenericMap[Key, int]{
+		"SyncMap":    hopmap.NewSync[Key, int](c),
+		"ShardedMap": hopmap.NewSharded[Key, int](8, c),
+		"RCUMap":     hopmap.NewRCU[Key, int](c),
+	} {
+		t.Run(name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			for w := 0; w < 8; w++ {
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+
+					for i := 0; i < 200; i++ {
+						k := Key(w*200 + i)
+						m.Put(k, i)
+						m.Get(k)
+						m.Get(Key(i))
+					}
+				}(w)
+			}
+			wg.Wait()
+
+			require.Equal(t, 1600, m.Len())
+		})
+	}
+}
+
+func TestSyncMapShrink(t *testing.T) {
+	c := hopmap.Config{Size: 1 << 12, BucketSize: 32, AutoResize: true, MaxLoad: 0.9, MinLoad: 0.25}
+	m := hopmap.NewSync[Key, int](c)
+
+	const n = 3000
+	for i := 0; i < n; i++ {
+		require.True(t, m.Put(Key(i), i))
+	}
+	grown := m.Size()
+
+	// readers look up the keys which are never deleted, while the writer shrinks the map
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			for i := w; ; i = (i + 1) % 100 {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				if v, ok := m.Get(Key(i)); !ok || v != i {
+					t.Errorf("key %d: got %d, %v", i, v, ok)
+					return
+				}
+			}
+		}(w)
+	}
+
+	for i := n - 1; i >= 100; i-- {
+		_, ok := m.Delete(Key(i))
+		require.True(t, ok)
+	}
+	close(done)
+	wg.Wait()
+
+	require.Equal(t, 100, m.Len())
+	require.Less(t, m.Size(), grown)
+	for i := 0; i < n; i++ {
+		v, ok := m.Get(Key(i))
+		require.Equal(t, i < 100, ok)
+		if ok {
+			require.Equal(t, i, v)
+		}
+	}
+}
+
+func TestRCUMapSnapshots(t *testing.T) {
+	r := hopmap.NewRCU[Key, int](hopmap.Config{Size: 1 << 10, BucketSize: 32, Stats: true})
+
+	// the writer moves units between two keys, so every snapshot must sum to the same total
+	r.Update(func(m *hopmap.Map[Key, int]) {
+		m.Put(0, 100)
+		m.Put(1, 0)
+	})
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				m := r.Load()
+				a, _ := m.Get(0)
+				b, _ := m.Get(1)
+				if a+b != 100 {
+					t.Errorf("inconsistent snapshot: %d + %d", a, b)
+					return
+				}
+				r.Get(2)
+			}
+		}()
+	}
+
+	for i := 0; i < 200; i++ {
+		r.Update(func(m *hopmap.Map[Key, int]) {
+			a, _ := m.Get(0)
+			b, _ := m.Get(1)
+			m.Put(0, a-1)
+			m.Put(1, b+1)
+		})
+		if i%10 == 0 {
+			r.Put(Key(i+2), i)
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	v, _ := r.Get(1)
+	require.Equal(t, 200, v)
+	require.Equal(t, 22, r.Len())
+}
+
+// BenchmarkSyncMapContention runs a parallel workload, with one write every writeEvery operations,
+// on a SyncMap guarded by a single lock and on a ShardedMap.
+func BenchmarkSyncMapContention(b *testing.B) {
+	c := hopmap.Config{Size: 1 << 16, BucketSize: 32}
+
+	for _, writeEvery := range []int{64, 2} {
+		impls := map[string]hopmap.GenericMap[Key, int]{
+			"SyncMap":    hopmap.NewSync[Key, int](c),
+			"ShardedMap": hopmap.NewSharded[Key, int](16, c),
+		}
+
+		for name, m := range impls {
+			for i := 0; i < 1<<14; i++ {
+				m.Put(Key(i), i)
+			}
+
+			b.Run(fmt.Sprintf("%s/writeEvery=%d", name, writeEvery), func(b *testing.B) {
+				b.RunParallel(func(pb *testing.PB) {
+					r := rand.New(rand.NewSource(rand.Int63()))
+					for i := 0; pb.Next(); i++ {
+						k := Key(r.Intn(1 << 14))
+						if i%writeEvery == 0 {
+							m.Put(k, i)
+						} else {
+							m.Get(k)
+						}
+					}
+				})
+			})
+		}
+	}
+}
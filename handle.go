@@ -0,0 +1,112 @@
+package hopmap
+
+// Handle refers to a key of a Map, so that reading, writing and deleting it through the handle
+// hashes the key and searches its neighborhood only once, as long as its entry stays in place.
+// Any change moving the entry, such as a reshift or a resize, invalidates the handle, which then
+// transparently resolves the key again on next use, hashing it only if the map has been resized.
+// A handle to an absent key is resolved again on each use. When entries are recycled
+// through Config.Allocator, the key of the entry is also compared on each use.
+type Handle[K Hashable[K], V any] struct {
+	m    *Map[K, V]
+	key  K
+	hash uint32 // home bucket of key in a table of the given size
+	size int
+
+	e        *entry[K, V] // entry of key when last resolved, or nil if absent
+	slot     int          // index of e in the table, or in the overflow set if overflow is set
+	overflow bool
+}
+
+// Lookup resolves key, returning a handle to it, whether it is in the map or not.
+func (m *Map[K, V]) Lookup(key K) Handle[K, V] {
+	h := Handle[K, V]{m: m, key: key, hash: m.hashKey(key), size: m.size}
+	h.resolve()
+	return h
+}
+
+func (h *Handle[K, V]) Key() K {
+	return h.key
+}
+
+// Value returns the value of the key, if it is in the map.
+func (h *Handle[K, V]) Value() (V, bool) {
+	e := h.ref()
+	h.m.countGet(e != nil)
+	if e == nil {
+		return zeroValue[V](), false
+	}
+	return (*e).value, true
+}
+
+// Set stores value under the key, as Put does.
+func (h *Handle[K, V]) Set(value V) bool {
+	if e := h.ref(); e != nil {
+		err := h.m.putExisting(e, value)
+		// CopyOnOverwrite may have replaced the entry
+		h.e = *e
+		return err == nil
+	}
+	// ref has resolved the key again, so its home bucket is current
+	return h.m.putNew(h.hash, h.key, value) == nil
+}
+
+// Delete removes the key from the map, as Map.Delete does.
+func (h *Handle[K, V]) Delete() (V, bool) {
+	if h.ref() == nil {
+		return zeroValue[V](), false
+	}
+
+	m := h.m
+	var value V
+	if h.overflow {
+		value = m.deleteOverflow(h.slot)
+	} else {
+		value = m.deleteAt(h.hash, h.slot)
+	}
+	h.e = nil
+
+	if m.shouldShrink() {
+		m.rehash(m.size / 2)
+	}
+	return value, true
+}
+
+// ref returns the location of the entry of the key, resolving the key again
+// if the entry has been moved, or nil if the key is not in the map.
+func (h *Handle[K, V]) ref() **entry[K, V] {
+	m := h.m
+	// after a resize, the entry may stay in its slot while its home bucket changes
+	if h.e != nil && h.size == m.size && (m.alloc == nil || h.e.key.Equals(h.key)) {
+		if h.overflow {
+			if h.slot < len(m.overflow) && m.overflow[h.slot] == h.e {
+				return &m.overflow[h.slot]
+			}
+		} else if h.slot < len(m.entries) && m.entries[h.slot] == h.e {
+			return &m.entries[h.slot]
+		}
+	}
+
+	if !h.resolve() {
+		return nil
+	}
+	if h.overflow {
+		return &m.overflow[h.slot]
+	}
+	return &m.entries[h.slot]
+}
+
+// resolve looks up the key, rehashing it if the map has been resized since it was last hashed.
+func (h *Handle[K, V]) resolve() bool {
+	m := h.m
+	if h.size != m.size {
+		h.hash, h.size = m.hashKey(h.key), m.size
+	}
+
+	h.e = nil
+	if j := m.findEntry(h.hash, h.key); j >= 0 {
+		h.e, h.slot, h.overflow = m.entries[j], j, false
+	} else if o := m.findOverflow(h.key); o >= 0 {
+		h.e, h.slot, h.overflow = m.overflow[o], o, true
+	}
+	return h.e != nil
+}
@@ -0,0 +1,111 @@
+package hopmap_test
+
+import (
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+// countedKey counts the calls to its HashCode.
+type countedKey struct {
+	k     uint32
+	calls *int
+}
+
+func (x countedKey) Equals(y countedKey) bool {
+	return x.k == y.k
+}
+
+func (x countedKey) HashCode() uint32 {
+	*x.calls++
+	return x.k
+}
+
+func TestHandle(t *testing.T) {
+	calls := 0
+	key := func(k uint32) countedKey { return countedKey{k, &calls} }
+
+	m := hopmap.New[countedKey, int](hopmap.Config{Size: 64, BucketSize: 4, Stats: true})
+	for i := uint32(0); i < 20; i++ {
+		require.True(t, m.Put(key(i), int(i)))
+	}
+
+	// reading, writing and deleting through the handle hash the key once
+	calls = 0
+	h := m.Lookup(key(5))
+	require.Equal(t, key(5), h.Key())
+
+	v, ok := h.Value()
+	require.True(t, ok)
+	require.Equal(t, 5, v)
+
+	require.True(t, h.Set(50))
+	v, _ = h.Value()
+	require.Equal(t, 50, v)
+	v, _ = m.Get(key(5))
+	require.Equal(t, 50, v)
+	require.Equal(t, 2, calls)
+
+	v, ok = h.Delete()
+	require.True(t, ok)
+	require.Equal(t, 50, v)
+	_, ok = h.Value()
+	require.False(t, ok)
+	_, ok = h.Delete()
+	require.False(t, ok)
+	require.Equal(t, 19, m.Len())
+
+	// setting an absent key inserts it
+	require.True(t, h.Set(5))
+	v, ok = m.Get(key(5))
+	require.True(t, ok)
+	require.Equal(t, 5, v)
+
+	// a resize invalidates the handle, which is resolved again
+	h = m.Lookup(key(7))
+	require.NoError(t, m.Resize(256))
+	calls = 0
+	v, ok = h.Value()
+	require.True(t, ok)
+	require.Equal(t, 7, v)
+	require.Equal(t, 1, calls)
+
+	// a handle to an absent key sees it once inserted through the map, past a cluster
+	h = m.Lookup(key(100))
+	for i := uint32(1); i < 4; i++ {
+		require.True(t, m.Put(key(100+i*256), 0))
+	}
+	require.True(t, m.Put(key(100), 100))
+	v, ok = h.Value()
+	require.True(t, ok)
+	require.Equal(t, 100, v)
+
+	m.Delete(key(100))
+	_, ok = h.Value()
+	require.False(t, ok)
+	require.NoError(t, m.Validate())
+}
+
+func TestHandleOverflow(t *testing.T) {
+	m := hopmap.New[Key, int](hopmap.Config{Size: 16, BucketSize: 2, AllowOverflow: true, CopyOnOverwrite: true})
+	for i := 0; i < 4; i++ {
+		require.True(t, m.Put(Key(i*16), i))
+	}
+
+	h := m.Lookup(48)
+	v, ok := h.Value()
+	require.True(t, ok)
+	require.Equal(t, 3, v)
+
+	require.True(t, h.Set(30))
+	require.True(t, h.Set(31))
+	v, _ = m.Get(48)
+	require.Equal(t, 31, v)
+
+	v, ok = h.Delete()
+	require.True(t, ok)
+	require.Equal(t, 31, v)
+	require.Equal(t, 3, m.Len())
+	require.NoError(t, m.Validate())
+}
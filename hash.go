@@ -0,0 +1,99 @@
+package hopmap
+
+import "fmt"
+
+// MurmurFinalizer is the finalizer of MurmurHash3, suitable as Config.HashFinalizer.
+func MurmurFinalizer(h uint32) uint32 {
+	return fmix32(h)
+}
+
+// finalize applies the HashFinalizer of c, if any, to h.
+func (c *Config) finalize(h uint32) uint32 {
+	if c.HashFinalizer != nil {
+		return c.HashFinalizer(h)
+	}
+	return h
+}
+
+// fmix32 is the finalizer of MurmurHash3, which makes every bit of h affect every bit of the result.
+func fmix32(h uint32) uint32 {
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
+
+// fmix64 is the 64-bit finalizer of MurmurHash3.
+func fmix64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// probeNeighborhood looks for an empty slot within the neighborhood of hash,
+// visiting offsets with a step coprime with BucketSize derived from a second hash of key.
+func (m *Map[K, V]) probeNeighborhood(hash uint32, key K) int {
+	h := m.config.BucketSize
+	if h > m.size {
+		h = m.size
+	}
+
+	step := probeStep(key.HashCode()^m.config.Seed, h)
+	for k, off := 0, 0; k < h; k, off = k+1, (off+step)%h {
+		if j := mod(int(hash)+off, m.size); m.entries[j] == nil {
+			return j
+		}
+	}
+	return -1
+}
+
+// stepSeed seeds the second hash of DoubleHashing.
+const stepSeed = 0x9e3779b9
+
+// probeStep returns the step, coprime with h, at which the neighborhood of size h of a key with the given
+// hash code (xored with Seed) is probed.
+// It comes from a mix seeded apart from HashFinalizer, so that keys sharing a home bucket still get
+// different steps, even when the finalizer is MurmurFinalizer itself.
+func probeStep(code uint32, h int) int {
+	step := 1
+	if h > 1 {
+		step += int(fmix32(code^stepSeed) % uint32(h-1))
+		for gcd(step, h) != 1 {
+			step++
+		}
+	}
+	return step
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// checkKey panics if key is not equal to itself, or if its hash code is not stable.
+func checkKey[K Hashable[K]](key K) {
+	if !key.Equals(key) {
+		panic(fmt.Sprintf("hopmap: key %v is not equal to itself", key))
+	}
+	if h1, h2 := key.HashCode(), key.HashCode(); h1 != h2 {
+		panic(fmt.Sprintf("hopmap: hash code of key %v is not stable: %d, then %d", key, h1, h2))
+	}
+}
+
+// hashableInt is only used in compile-time interface assertions.
+type hashableInt int
+
+func (x hashableInt) Equals(y hashableInt) bool {
+	return x == y
+}
+
+func (x hashableInt) HashCode() uint32 {
+	return uint32(x)
+}
@@ -0,0 +1,206 @@
+package hopmap
+
+import "math/bits"
+
+// inlineSlots stores the keys and values of an inlineTable by value, without entry pointers.
+// The maps built on inlineTable only differ in how their slots lay out keys and values.
+type inlineSlots[K, V, S any] interface {
+	// alloc returns empty slots for a table of the given size.
+	alloc(size int) S
+	key(j int) K
+	value(j int) V
+	set(j int, key K, value V)
+	setValue(j int, value V)
+	// move moves the entry of slot from into the empty slot to, clearing from.
+	move(from, to int)
+	clear(j int)
+}
+
+// inlineTable is the hopscotch table of the maps storing entries inline, ValueMap and ColumnarMap.
+type inlineTable[K Hashable[K], V any, S inlineSlots[K, V, S]] struct {
+	config    Config
+	slots     S
+	neighbors []uint32
+	present   []uint64
+	size, n   int
+}
+
+// newInlineTable creates a table from the given config, rounding Size as New does.
+// It panics if the config is invalid.
+func newInlineTable[K Hashable[K], V any, S inlineSlots[K, V, S]](c Config) inlineTable[K, V, S] {
+	if err := c.validate(); err != nil {
+		panic(err)
+	}
+
+	c.Size = roundSize(c.Size)
+	var slots S
+	return inlineTable[K, V, S]{
+		config:    c,
+		slots:     slots.alloc(c.Size),
+		neighbors: make([]uint32, c.Size),
+		present:   make([]uint64, presentWords(c.Size)),
+		size:      c.Size,
+	}
+}
+
+func (m *inlineTable[K, V, S]) Get(key K) (V, bool) {
+	if j := m.findEntry(m.hashKey(key), key); j >= 0 {
+		return m.slots.value(j), true
+	}
+	return zeroValue[V](), false
+}
+
+func (m *inlineTable[K, V, S]) Put(key K, value V) bool {
+	if j := m.findEntry(m.hashKey(key), key); j >= 0 {
+		m.slots.setValue(j, value)
+		return true
+	}
+
+	if m.config.AutoResize && m.config.MaxLoad > 0 && float64(m.n+1)/float64(m.size) > m.config.MaxLoad {
+		m.grow()
+	}
+
+	// as in Map, grow until the key fits, unless its neighborhood is full of keys with its hash code
+	for !m.insert(key, value) {
+		if !m.config.AutoResize || m.crowdedBy(key) || !m.grow() {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *inlineTable[K, V, S]) Delete(key K) (V, bool) {
+	hash := m.hashKey(key)
+	j := m.findEntry(hash, key)
+	if j < 0 {
+		return zeroValue[V](), false
+	}
+
+	value := m.slots.value(j)
+	m.neighbors[hash] &^= 1 << (31 - mod(j-int(hash), m.size))
+	m.slots.clear(j)
+	m.present[j>>6] &^= 1 << (j & 63)
+	m.n--
+	return value, true
+}
+
+func (m *inlineTable[_, _, _]) Len() int {
+	return m.n
+}
+
+func (m *inlineTable[_, _, _]) Size() int {
+	return m.size
+}
+
+// Range calls fn for each entry of the map, stopping as soon as fn returns false.
+func (m *inlineTable[K, V, S]) Range(fn func(K, V) bool) {
+	for w, word := range m.present {
+		for ; word != 0; word &= word - 1 {
+			j := w<<6 + bits.TrailingZeros64(word)
+			if !fn(m.slots.key(j), m.slots.value(j)) {
+				return
+			}
+		}
+	}
+}
+
+func (m *inlineTable[K, V, S]) hashKey(key K) uint32 {
+	h := m.config.finalize(key.HashCode() ^ m.config.Seed)
+	return h % uint32(m.size)
+}
+
+func (m *inlineTable[K, V, S]) findEntry(hash uint32, key K) int {
+	for nb, j := m.neighbors[hash], int(hash); nb != 0; nb, j = nb<<1, j+1 {
+		if nb&(1<<31) != 0 && m.slots.key(mod(j, m.size)).Equals(key) {
+			return mod(j, m.size)
+		}
+	}
+	return -1
+}
+
+// insert places a key known not to be in the map.
+func (m *inlineTable[K, V, S]) insert(key K, value V) bool {
+	hash := int(m.hashKey(key))
+
+	j := m.findEmptySlot(hash)
+	for j >= 0 && mod(j-hash, m.size) >= m.config.BucketSize {
+		j = m.reshift(j)
+	}
+	if j < 0 {
+		return false
+	}
+
+	m.slots.set(j, key, value)
+	m.present[j>>6] |= 1 << (j & 63)
+	m.neighbors[hash] |= 1 << (31 - mod(j-hash, m.size))
+	m.n++
+	return true
+}
+
+// findEmptySlot returns the first empty slot from start, wrapping around, or -1.
+func (m *inlineTable[K, V, S]) findEmptySlot(start int) int {
+	for i := 0; i < m.config.probeLimit(m.size); i++ {
+		if j := mod(start+i, m.size); m.present[j>>6]&(1<<(j&63)) == 0 {
+			return j
+		}
+	}
+	return -1
+}
+
+// reshift moves into the empty slot j an entry of one of the preceding buckets which can reach it,
+// and returns the slot it was moved from, or -1 if there is none.
+func (m *inlineTable[K, V, S]) reshift(j int) int {
+	for back := m.config.BucketSize - 1; back > 0; back-- {
+		b := mod(j-back, m.size)
+		if off := bits.LeadingZeros32(m.neighbors[b]); off < back {
+			k := mod(b+off, m.size)
+			m.slots.move(k, j)
+			m.present[j>>6] |= 1 << (j & 63)
+			m.present[k>>6] &^= 1 << (k & 63)
+			m.neighbors[b] = m.neighbors[b]&^(1<<(31-off)) | 1<<(31-back)
+			return k
+		}
+	}
+	return -1
+}
+
+// crowdedBy reports whether the neighborhood of key is full of keys with its hash code, like Map.crowdedBy.
+func (m *inlineTable[K, V, S]) crowdedBy(key K) bool {
+	hash := int(m.hashKey(key))
+	if bits.OnesCount32(m.neighbors[hash]) < m.config.BucketSize {
+		return false
+	}
+
+	code := key.HashCode()
+	for off := 0; off < m.config.BucketSize; off++ {
+		if m.slots.key(mod(hash+off, m.size)).HashCode() != code {
+			return false
+		}
+	}
+	return true
+}
+
+// grow doubles the size of the map until all the entries fit, up to growLimit.
+func (m *inlineTable[K, V, S]) grow() bool {
+	limit := growLimit(m.config, m.n)
+	for size := uint64(m.size); ; {
+		size = min(2*size, MaxSize)
+		if size <= uint64(m.size) || size > limit {
+			return false
+		}
+
+		c := m.config
+		c.Size = int(size)
+		g := newInlineTable[K, V, S](c)
+
+		ok := true
+		m.Range(func(k K, v V) bool {
+			ok = g.insert(k, v)
+			return ok
+		})
+		if ok {
+			*m = g
+			return true
+		}
+	}
+}
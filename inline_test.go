@@ -0,0 +1,109 @@
+package hopmap_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+// inlineMap is implemented by the maps storing their entries inline.
+type inlineMap[K hopmap.Hashable[K], V any] interface {
+	hopmap.GenericMap[K, V]
+	Size() int
+}
+
+func TestInlineMaps(t *testing.T) {
+	impls := []struct {
+		name     string
+		new      func(hopmap.Config) inlineMap[Key, int]
+		newConst func(hopmap.Config) inlineMap[constKey, int]
+	}{
+		{
+			name:     "ValueMap",
+			new:      func(c hopmap.Config) inlineMap[Key, int] { return hopmap.NewValue[Key, int](c) },
+			newConst: func(c hopmap.Config) inlineMap[constKey, int] { return hopmap.NewValue[constKey, int](c) },
+		},
+		{
+			name:     "ColumnarMap",
+			new:      func(c hopmap.Config) inlineMap[Key, int] { return hopmap.NewColumnar[Key, int](c) },
+			newConst: func(c hopmap.Config) inlineMap[constKey, int] { return hopmap.NewColumnar[constKey, int](c) },
+		},
+	}
+
+	for _, impl := range impls {
+		t.Run(impl.name, func(t *testing.T) {
+			m := impl.new(hopmap.Config{Size: 1 << 8, BucketSize: 8, AutoResize: true, MaxLoad: 0.9})
+
+			r := rand.New(rand.NewSource(1))
+			want := make(map[Key]int)
+			for i := 0; i < 20000; i++ {
+				k := Key(r.Intn(1 << 12))
+				switch r.Intn(3) {
+				case 0:
+					v, ok := m.Delete(k)
+					require.Equal(t, want[k], v)
+					_, found := want[k]
+					require.Equal(t, found, ok)
+					delete(want, k)
+				default:
+					require.True(t, m.Put(k, i))
+					want[k] = i
+				}
+			}
+			require.Equal(t, len(want), m.Len())
+
+			for k, v := range want {
+				got, ok := m.Get(k)
+				require.True(t, ok)
+				require.Equal(t, v, got)
+			}
+
+			seen := 0
+			m.Range(func(k Key, v int) bool {
+				require.Equal(t, want[k], v)
+				seen++
+				return true
+			})
+			require.Equal(t, len(want), seen)
+
+			if cm, ok := m.(*hopmap.ColumnarMap[Key, int]); ok {
+				sum, wantSum := 0, 0
+				cm.RangeValues(func(v int) bool {
+					sum += v
+					return true
+				})
+				for _, v := range want {
+					wantSum += v
+				}
+				require.Equal(t, wantSum, sum)
+			}
+		})
+
+		t.Run(impl.name+"/Full", func(t *testing.T) {
+			m := impl.newConst(hopmap.Config{Size: 16, BucketSize: 4})
+			for i := 0; i < 4; i++ {
+				require.True(t, m.Put(constKey(i), i))
+			}
+			require.False(t, m.Put(4, 4))
+			require.Equal(t, 4, m.Len())
+
+			// growing cannot separate keys sharing a hash code
+			resizing := impl.newConst(hopmap.Config{Size: 16, BucketSize: 8, AutoResize: true})
+			for i := 0; i < 16; i++ {
+				require.Equal(t, i < 8, resizing.Put(constKey(i), i))
+			}
+			require.Equal(t, 16, resizing.Size())
+		})
+
+		t.Run(impl.name+"/InvalidConfig", func(t *testing.T) {
+			require.PanicsWithError(t, "hopmap: invalid config: bucket size 40 must be in [1, 32]", func() {
+				impl.new(hopmap.Config{Size: 16, BucketSize: 40})
+			})
+			require.Panics(t, func() {
+				impl.new(hopmap.Config{Size: 0, BucketSize: 8})
+			})
+		})
+	}
+}
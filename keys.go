@@ -0,0 +1,22 @@
+package hopmap
+
+import (
+	"bytes"
+	"hash/maphash"
+)
+
+// bytesSeed is shared by all Bytes keys, so that equal keys hash equally within the process.
+var bytesSeed = maphash.MakeSeed()
+
+// Bytes is a Hashable byte slice, allowing binary blobs to be used as keys.
+// Hash codes are randomized per process, and the slice must not be modified while in a map.
+type Bytes []byte
+
+func (x Bytes) Equals(y Bytes) bool {
+	return bytes.Equal(x, y)
+}
+
+func (x Bytes) HashCode() uint32 {
+	h := maphash.Bytes(bytesSeed, x)
+	return uint32(h ^ h>>32)
+}
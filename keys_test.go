@@ -0,0 +1,156 @@
+package hopmap_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/maphash"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBytes(t *testing.T) {
+	require.True(t, hopmap.Bytes(nil).Equals(hopmap.Bytes{}))
+	require.Equal(t, hopmap.Bytes(nil).HashCode(), hopmap.Bytes{}.HashCode())
+	require.False(t, hopmap.Bytes{0}.Equals(hopmap.Bytes{}))
+	require.False(t, hopmap.Bytes{0, 1}.Equals(hopmap.Bytes{1, 0}))
+
+	m := hopmap.New[hopmap.Bytes, int](hopmap.Config{Size: 1 << 12, BucketSize: 32})
+
+	// keys differing in a single byte, including zero padding
+	const n = 1 << 10
+	for i := 0; i < n; i++ {
+		k := make([]byte, 8)
+		binary.LittleEndian.PutUint64(k, uint64(i))
+		require.True(t, m.Put(k, i))
+	}
+
+	large := bytes.Repeat([]byte{0xab}, 1<<20)
+	require.True(t, m.Put(nil, -1))
+	require.True(t, m.Put(large, -2))
+	require.Equal(t, n+2, m.Len())
+
+	for i := 0; i < n; i++ {
+		k := make([]byte, 8)
+		binary.LittleEndian.PutUint64(k, uint64(i))
+		v, ok := m.Get(k)
+		require.True(t, ok)
+		require.Equal(t, i, v)
+	}
+
+	v, ok := m.Get(hopmap.Bytes{})
+	require.True(t, ok)
+	require.Equal(t, -1, v)
+
+	v, ok = m.Get(bytes.Clone(large))
+	require.True(t, ok)
+	require.Equal(t, -2, v)
+
+	_, ok = m.Get(large[1:])
+	require.False(t, ok)
+
+	// sequential keys must spread across buckets about as well as random ones
+	r := m.HashDistribution()
+	require.Less(t, r.ChiSquared, 1.5*float64(m.Size()))
+}
+
+// floatKey wraps a float, so NaN is not equal to itself.
+type floatKey float64
+
+func (x floatKey) Equals(y floatKey) bool {
+	return x == y
+}
+
+func (x floatKey) HashCode() uint32 {
+	return uint32(math.Float64bits(float64(x)))
+}
+
+// driftingKey returns a different hash code at each call.
+type driftingKey struct{ calls *uint32 }
+
+func (x driftingKey) Equals(y driftingKey) bool {
+	return x.calls == y.calls
+}
+
+func (x driftingKey) HashCode() uint32 {
+	*x.calls++
+	return *x.calls
+}
+
+func TestDebugCheckKeys(t *testing.T) {
+	floats := hopmap.New[floatKey, int](hopmap.Config{Size: 16, BucketSize: 8, DebugCheckKeys: true})
+	require.True(t, floats.Put(1.5, 1))
+	require.PanicsWithValue(t, "hopmap: key NaN is not equal to itself", func() {
+		floats.Put(floatKey(math.NaN()), 0)
+	})
+	require.Equal(t, 1, floats.Len())
+
+	drifting := hopmap.New[driftingKey, int](hopmap.Config{Size: 16, BucketSize: 8, DebugCheckKeys: true})
+	require.Panics(t, func() {
+		drifting.Put(driftingKey{new(uint32)}, 0)
+	})
+
+	// without checks, NaN keys are silently stored and never found again
+	unchecked := hopmap.New[floatKey, int](hopmap.Config{Size: 16, BucketSize: 8})
+	require.True(t, unchecked.Put(floatKey(math.NaN()), 0))
+	_, ok := unchecked.Get(floatKey(math.NaN()))
+	require.False(t, ok)
+}
+
+// mixedSeed is picked once per test run, so that placement is not tied to a particular seed.
+var mixedSeed = maphash.MakeSeed()
+
+// mixedKey is a realistic key, whose hash code is well mixed, unlike that of Key,
+// so that tests built on it exercise real probe chains and reshifts.
+type mixedKey uint32
+
+func (x mixedKey) Equals(y mixedKey) bool {
+	return x == y
+}
+
+func (x mixedKey) HashCode() uint32 {
+	return uint32(maphash.Comparable(mixedSeed, x))
+}
+
+func TestMixedKeyChurn(t *testing.T) {
+	for name, c := range map[string]hopmap.Config{
+		"Forward":       {Size: 1 << 10, BucketSize: 8, AutoResize: true, MaxLoad: 0.9, Stats: true},
+		"Bidirectional": {Size: 1 << 10, BucketSize: 8, AutoResize: true, MaxLoad: 0.9, ProbeStrategy: hopmap.Bidirectional},
+		"DoubleHashing": {Size: 1 << 10, BucketSize: 8, AutoResize: true, MaxLoad: 0.9, DoubleHashing: true},
+		"Tombstones":    {Size: 1 << 10, BucketSize: 8, AutoResize: true, MaxLoad: 0.9, UseTombstones: true},
+		"Shrink":        {Size: 1 << 10, BucketSize: 8, AutoResize: true, MaxLoad: 0.9, MinLoad: 0.3},
+		"Overflow":      {Size: 1 << 10, BucketSize: 4, AllowOverflow: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			m := hopmap.New[mixedKey, int](c)
+			ref := make(map[mixedKey]int)
+
+			r := rand.New(rand.NewSource(1))
+			for i := 0; i < 50000; i++ {
+				// the key space drifts, so that the map grows and shrinks
+				k := mixedKey(r.Intn(2000) + i/25)
+				if _, ok := ref[k]; ok && r.Intn(2) == 0 {
+					m.Delete(k)
+					delete(ref, k)
+				} else if m.Put(k, i) {
+					ref[k] = i
+				}
+
+				if i%5000 == 0 {
+					require.NoError(t, m.Validate())
+				}
+			}
+			require.NoError(t, m.Validate())
+
+			require.Equal(t, len(ref), m.Len())
+			for k, v := range ref {
+				got, ok := m.Get(k)
+				require.True(t, ok)
+				require.Equal(t, v, got)
+			}
+		})
+	}
+}
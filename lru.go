@@ -0,0 +1,200 @@
+package hopmap
+
+import "fmt"
+
+// LRUConfig configures an LRUMap.
+type LRUConfig[K, V any] struct {
+	Config
+	// Capacity is the maximum number of entries, beyond which the least recently used one is evicted.
+	Capacity int
+	// OnEvict, if set, is called with each evicted entry, after it has been removed from the map.
+	OnEvict func(K, V)
+}
+
+type lruNode[K, V any] struct {
+	key        K
+	value      V
+	cost       int64
+	prev, next *lruNode[K, V]
+}
+
+// LRUMap is a map bounded to a fixed number of entries, which evicts the least recently used entry
+// when full. Both Get and Put mark an entry as recently used.
+type LRUMap[K Hashable[K], V any] struct {
+	m        *Map[K, *lruNode[K, V]]
+	root     lruNode[K, V] // sentinel: root.next is the most recently used entry
+	capacity int
+	onEvict  func(K, V)
+
+	// cost bounds are only used by CostMap, where each entry has its own cost
+	cost, maxCost int64
+	// insertionOrder is only used by FIFOMap, whose entries are not promoted when accessed
+	insertionOrder bool
+}
+
+// NewLRU creates an LRUMap. It panics if Capacity is not positive.
+func NewLRU[K Hashable[K], V any](c LRUConfig[K, V]) *LRUMap[K, V] {
+	if c.Capacity <= 0 {
+		panic(fmt.Errorf("%w: capacity %d must be positive", ErrInvalidConfig, c.Capacity))
+	}
+
+	l := &LRUMap[K, V]{
+		m:        New[K, *lruNode[K, V]](c.Config),
+		capacity: c.Capacity,
+		onEvict:  c.OnEvict,
+	}
+	l.root.next, l.root.prev = &l.root, &l.root
+	return l
+}
+
+func (l *LRUMap[K, V]) Get(key K) (V, bool) {
+	node, ok := l.m.Get(key)
+	if !ok {
+		return zeroValue[V](), false
+	}
+	if !l.insertionOrder {
+		l.moveToFront(node)
+	}
+	return node.value, true
+}
+
+func (l *LRUMap[K, V]) Put(key K, value V) bool {
+	return l.put(key, value, 0)
+}
+
+func (l *LRUMap[K, V]) put(key K, value V, cost int64) bool {
+	node, ok := l.m.Get(key)
+	if ok {
+		if !l.fits(node, l.m.Len(), l.cost-node.cost+cost) {
+			return false
+		}
+		node.value = value
+		l.cost += cost - node.cost
+		node.cost = cost
+		if !l.insertionOrder {
+			l.moveToFront(node)
+		}
+	} else {
+		node = &lruNode[K, V]{key: key, value: value, cost: cost}
+		if !l.fits(node, l.m.Len()+1, l.cost+cost) || !l.insert(node) {
+			return false
+		}
+	}
+
+	for l.exceeds(l.m.Len(), l.cost) {
+		l.evict(l.victim(node))
+	}
+	return true
+}
+
+// fits reports whether the map, once holding count entries of the given total cost, can be brought
+// back within its bounds by evicting unpinned entries other than node, which is being written.
+func (l *LRUMap[K, V]) fits(node *lruNode[K, V], count int, cost int64) bool {
+	for v := l.root.prev; v != &l.root && l.exceeds(count, cost); v = v.prev {
+		if v != node && !l.m.IsPinned(v.key) {
+			count--
+			cost -= v.cost
+		}
+	}
+	return !l.exceeds(count, cost)
+}
+
+func (l *LRUMap[K, V]) exceeds(count int, cost int64) bool {
+	return count > l.capacity || (l.maxCost > 0 && cost > l.maxCost)
+}
+
+func (l *LRUMap[K, V]) Delete(key K) (V, bool) {
+	node, ok := l.m.Delete(key)
+	if !ok {
+		return zeroValue[V](), false
+	}
+	l.unlink(node)
+	l.cost -= node.cost
+	return node.value, true
+}
+
+// Pin prevents key from being evicted, reporting whether it is in the map.
+// A Put which would take the map beyond its bounds, with too few unpinned entries left to evict, is rejected.
+func (l *LRUMap[K, V]) Pin(key K) bool {
+	return l.m.Pin(key)
+}
+
+func (l *LRUMap[K, V]) Unpin(key K) bool {
+	return l.m.Unpin(key)
+}
+
+func (l *LRUMap[K, V]) IsPinned(key K) bool {
+	return l.m.IsPinned(key)
+}
+
+func (l *LRUMap[K, V]) Len() int {
+	return l.m.Len()
+}
+
+// insert adds node to the map. If the table is full while the map is at capacity, the least recently used
+// entry is taken out to make room, but it is only evicted once node is in: otherwise it is put back.
+func (l *LRUMap[K, V]) insert(node *lruNode[K, V]) bool {
+	if !l.m.Put(node.key, node) {
+		victim := l.victim(node)
+		if l.m.Len() < l.capacity || victim == nil {
+			return false
+		}
+
+		l.m.Delete(victim.key)
+		ok := l.m.Put(node.key, node)
+		if !ok && l.m.Put(victim.key, victim) {
+			return false
+		}
+		// the victim is gone, either to make room for node, or because it no longer fits itself
+		l.evicted(victim)
+		if !ok {
+			return false
+		}
+	}
+
+	l.cost += node.cost
+	l.pushFront(node)
+	return true
+}
+
+// victim returns the least recently used entry other than except which is not pinned, or nil if there is none.
+func (l *LRUMap[K, V]) victim(except *lruNode[K, V]) *lruNode[K, V] {
+	for node := l.root.prev; node != &l.root; node = node.prev {
+		if node != except && !l.m.IsPinned(node.key) {
+			return node
+		}
+	}
+	return nil
+}
+
+func (l *LRUMap[K, V]) evict(node *lruNode[K, V]) {
+	l.m.Delete(node.key)
+	l.evicted(node)
+}
+
+// evicted removes node, already deleted from the map, from the eviction order.
+func (l *LRUMap[K, V]) evicted(node *lruNode[K, V]) {
+	l.unlink(node)
+	l.cost -= node.cost
+
+	if l.onEvict != nil {
+		l.onEvict(node.key, node.value)
+	}
+}
+
+func (l *LRUMap[K, V]) pushFront(node *lruNode[K, V]) {
+	node.prev, node.next = &l.root, l.root.next
+	l.root.next.prev = node
+	l.root.next = node
+}
+
+func (l *LRUMap[K, V]) unlink(node *lruNode[K, V]) {
+	node.prev.next = node.next
+	node.next.prev = node.prev
+	node.prev, node.next = nil, nil
+}
+
+func (l *LRUMap[K, V]) moveToFront(node *lruNode[K, V]) {
+	l.unlink(node)
+	l.pushFront(node)
+}
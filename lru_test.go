@@ -0,0 +1,127 @@
+package hopmap_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUEviction(t *testing.T) {
+	var (
+		m       *hopmap.LRUMap[Key, string]
+		evicted []Key
+	)
+
+	m = hopmap.NewLRU(hopmap.LRUConfig[Key, string]{
+		Config:   hopmap.Config{Size: 16, BucketSize: 8},
+		Capacity: 2,
+		OnEvict: func(k Key, v string) {
+			evicted = append(evicted, k)
+			require.Equal(t, "two", v)
+
+			_, ok := m.Get(k)
+			require.False(t, ok)
+		},
+	})
+
+	m.Put(1, "one")
+	m.Put(2, "two")
+	m.Get(1)
+	m.Put(3, "three")
+
+	require.Equal(t, []Key{2}, evicted)
+	require.Equal(t, 2, m.Len())
+
+	_, ok := m.Get(1)
+	require.True(t, ok)
+	_, ok = m.Get(3)
+	require.True(t, ok)
+}
+
+func TestLRUPin(t *testing.T) {
+	var evicted []Key
+	m := hopmap.NewLRU(hopmap.LRUConfig[Key, int]{
+		Config:   hopmap.Config{Size: 16, BucketSize: 8},
+		Capacity: 3,
+		OnEvict:  func(k Key, v int) { evicted = append(evicted, k) },
+	})
+
+	m.Put(1, 1)
+	require.True(t, m.Pin(1))
+	require.False(t, m.Pin(42))
+
+	for i := 2; i < 8; i++ {
+		m.Put(Key(i), i)
+	}
+	require.Equal(t, []Key{2, 3, 4, 5}, evicted)
+	require.True(t, m.IsPinned(1))
+
+	_, ok := m.Get(1)
+	require.True(t, ok)
+
+	// once unpinned, the key is the least recently used one again
+	require.True(t, m.Unpin(1))
+	m.Put(8, 8)
+	require.Equal(t, []Key{2, 3, 4, 5, 6}, evicted)
+	m.Put(9, 9)
+	require.Equal(t, []Key{2, 3, 4, 5, 6, 7}, evicted)
+
+	// when all the other entries are pinned, there is no room for a new one
+	for _, k := range []Key{1, 8, 9} {
+		require.True(t, m.Pin(k))
+	}
+	require.False(t, m.Put(10, 10))
+	require.Equal(t, 3, m.Len())
+	require.Equal(t, []Key{2, 3, 4, 5, 6, 7}, evicted)
+
+	_, ok = m.Get(10)
+	require.False(t, ok)
+}
+
+func TestLRUFullTable(t *testing.T) {
+	m := hopmap.NewLRU(hopmap.LRUConfig[Key, int]{
+		Config:   hopmap.Config{Size: 4, BucketSize: 4},
+		Capacity: 4,
+	})
+
+	for i := 0; i < 16; i++ {
+		require.True(t, m.Put(Key(i), i))
+	}
+	require.Equal(t, 4, m.Len())
+
+	_, ok := m.Get(15)
+	require.True(t, ok)
+}
+
+func TestLRUFailedInsert(t *testing.T) {
+	var evicted []Key
+	m := hopmap.NewLRU(hopmap.LRUConfig[Key, int]{
+		Config:   hopmap.Config{Size: 16, BucketSize: 4},
+		Capacity: 5,
+		OnEvict:  func(k Key, v int) { evicted = append(evicted, k) },
+	})
+
+	// the least recently used key lies away from bucket 0, whose neighborhood is full
+	require.True(t, m.Put(8, 8))
+	for i := 0; i < 4; i++ {
+		require.True(t, m.Put(Key(i*16), i))
+	}
+
+	// evicting key 8 cannot make room for another key of bucket 0, so it stays
+	require.False(t, m.Put(64, 64))
+	require.Empty(t, evicted)
+	require.Equal(t, 5, m.Len())
+	_, ok := m.Get(8)
+	require.True(t, ok)
+}
+
+func TestLRUInvalidCapacity(t *testing.T) {
+	for _, capacity := range []int{0, -1} {
+		msg := fmt.Sprintf("hopmap: invalid config: capacity %d must be positive", capacity)
+		require.PanicsWithError(t, msg, func() {
+			hopmap.NewLRU(hopmap.LRUConfig[Key, int]{Config: hopmap.Config{Size: 16, BucketSize: 8}, Capacity: capacity})
+		})
+	}
+}
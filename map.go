@@ -1,8 +1,13 @@
 package hopmap
 
 import (
+	"context"
+	"fmt"
+	"math"
 	"math/bits"
 	"reflect"
+	"sync/atomic"
+	"unsafe"
 )
 
 type Hashable[K any] interface {
@@ -10,37 +15,243 @@ type Hashable[K any] interface {
 	HashCode() uint32
 }
 
+// GenericMap is implemented by all the map variants of the package,
+// allowing code to be written independently of the one in use.
+type GenericMap[K Hashable[K], V any] interface {
+	Get(K) (V, bool)
+	Put(K, V) bool
+	Delete(K) (V, bool)
+	Len() int
+	Range(func(K, V) bool)
+}
+
+var _ GenericMap[hashableInt, int] = (*Map[hashableInt, int])(nil)
+
 type Config struct {
 	Size, BucketSize int
+	// AutoResize makes the map grow when an insertion fails, or when MaxLoad is exceeded.
+	// It requires a BucketSize of at least MinAutoResizeBucketSize, since smaller neighborhoods
+	// overflow on random keys long before the table fills up. Growing stops before the load would
+	// drop below 1/8, or half of MaxLoad if lower, on all but small tables: past that, a key which
+	// cannot be placed is reported as ErrTableFull rather than exhausting memory.
+	AutoResize bool
+	MaxLoad    float64
+	// MinLoad is the load factor below which an auto-resizing map halves its size on Delete.
+	// To avoid oscillating between sizes, it only shrinks when the halved table would stay
+	// at most halfway between MinLoad and MaxLoad, unless EagerResize is set. Zero disables shrinking.
+	MinLoad float64
+	// EagerResize makes sizing depend on load alone: the map shrinks as soon as its load drops below MinLoad,
+	// even if this makes it grow back on the next insertion. It favours tight memory over stability.
+	EagerResize bool
+	// Seed randomizes the home buckets of keys. Maps sharing a seed place
+	// the same sequence of operations identically, which makes placement reproducible.
+	Seed  uint32
+	Stats bool
+	// CopyOnOverwrite makes Put allocate a fresh entry when overwriting a key,
+	// so that pointers returned by GetPointer keep seeing the old value.
+	CopyOnOverwrite bool
+	// AllowOverflow stores keys which cannot be placed in the table into a linearly scanned
+	// overflow set. This is tried before growing the table, even with AutoResize.
+	AllowOverflow bool
+	// OverflowCapacity is the initial capacity of the overflow set, which grows
+	// by a factor of OverflowGrowth (2 if unset) each time it fills up.
+	OverflowCapacity int
+	OverflowGrowth   float64
+	// DoubleHashing makes insertions probe the neighborhood of the home bucket
+	// with a step derived from a second hash of the key, before falling back to linear probing.
+	// This spreads clustered keys over their neighborhoods, leaving room for the keys of nearby buckets.
+	DoubleHashing bool
+	// ProbeStrategy controls the direction in which insertions look for an empty slot.
+	ProbeStrategy ProbeStrategy
+	// UseTombstones makes Delete leave a tombstone in the slot of the removed entry,
+	// keeping its neighbor bit, so that inserting a key homed in the same bucket reclaims
+	// the slot without probing or reshifting. Tombstones are purged once they outnumber the empty slots.
+	UseTombstones bool
+	// DebugCheckKeys makes insertions panic on keys which are not equal to themselves,
+	// or whose hash code changes between calls, since they would silently corrupt the map.
+	DebugCheckKeys bool
+	// OnDuplicate controls what Put and TryPut do when the key is already in the map.
+	OnDuplicate DuplicatePolicy
+	// HashFinalizer, if set, mixes the bits of hash codes (xored with Seed) before they are reduced to a home bucket,
+	// so that hash codes differing only in their high bits still spread across buckets.
+	// DefaultConfig sets it to MurmurFinalizer, while a nil finalizer uses hash codes as they are.
+	HashFinalizer func(uint32) uint32
+	// OnReshift, if set, is called each time an insertion relocates an entry from one slot to another
+	// to make room, which helps tuning BucketSize and Size: long cascades of calls for a single
+	// insertion mean that neighborhoods are crowded.
+	OnReshift func(from, to int)
+	// MaxProbeBuckets, if positive, bounds the search for an empty slot during insertions
+	// to MaxProbeBuckets*BucketSize slots, past which the insertion fails, or grows the map,
+	// as if the table were full. On crowded tables without AutoResize, this keeps failing
+	// insertions from scanning the whole table, at the cost of failing some which could succeed.
+	MaxProbeBuckets int
+	// MaxReshift, if positive, bounds the number of entries an insertion may relocate to bring
+	// an empty slot into the neighborhood of the key. Past it, the insertion fails, or grows the map,
+	// as if the table were full, rather than cascading through a long cluster.
+	// Entries already relocated stay where they are, which keeps the map valid.
+	MaxReshift int
+	// Allocator, if set, is an Allocator[K, V] matching the types of the map. It provides the entries
+	// of the map, which hands them back once deleted, so that they can be recycled, e.g. from an arena.
+	// Entries replaced under CopyOnOverwrite are left to the garbage collector, since pointers
+	// returned by GetPointer may still refer to them. New panics on an allocator of other types,
+	// and NewE returns an error. Maps derived from m with other types, e.g. by GroupBy, do not use it.
+	Allocator any
+}
+
+// probeLimit returns how many slots an insertion may probe in a table of the given size.
+func (c Config) probeLimit(size int) int {
+	if c.MaxProbeBuckets > 0 && c.MaxProbeBuckets*c.BucketSize < size {
+		return c.MaxProbeBuckets * c.BucketSize
+	}
+	return size
+}
+
+// ProbeStrategy is the direction in which insertions look for an empty slot.
+type ProbeStrategy int
+
+const (
+	// Forward looks for the first empty slot following the home bucket.
+	Forward ProbeStrategy = iota
+	// Bidirectional looks for the nearest empty slot in both directions, alternating between them.
+	// A slot preceding the home bucket is then moved past it by pulling entries backwards.
+	Bidirectional
+)
+
+// DuplicatePolicy is the behavior of Put and TryPut for keys already in the map.
+type DuplicatePolicy int
+
+const (
+	// Overwrite replaces the stored value.
+	Overwrite DuplicatePolicy = iota
+	// KeepExisting leaves the stored value untouched, and still reports success.
+	KeepExisting
+	// FailOnDuplicate leaves the stored value untouched, and makes TryPut report ErrDuplicateKey.
+	FailOnDuplicate
+)
+
+// MaxSize is the largest supported Size. Home buckets are addressed by uint32 hashes,
+// and slot indexes plus a bucket offset must not overflow int, which caps it on 32-bit platforms.
+const MaxSize = min(math.MaxUint32, math.MaxInt-64)
+
+// MinAutoResizeBucketSize is the smallest BucketSize supported with AutoResize.
+const MinAutoResizeBucketSize = 8
+
+const (
+	// minGrowLoad is the load below which growing the map for a failed insertion is given up.
+	minGrowLoad = 1.0 / 8
+	// minGrowLimit is the size up to which the map may grow regardless of its load,
+	// since keys colliding on small tables only need a few more bits of their hashes.
+	minGrowLimit = 1 << 10
+)
+
+// growLimit returns the largest size a map configured by c and holding n entries may grow to
+// in order to place one more.
+func growLimit(c Config, n int) uint64 {
+	floor := minGrowLoad
+	if c.MaxLoad > 0 {
+		floor = min(floor, c.MaxLoad/2)
+	}
+	return min(max(uint64(float64(n+1)/floor), minGrowLimit), MaxSize)
+}
+
+// Clone returns a copy of c, which can be modified without affecting c.
+func (c Config) Clone() Config {
+	return c
 }
 
 func DefaultConfig() Config {
 	return Config{
-		Size:       1 << 16,
-		BucketSize: 32,
+		Size:          1 << 16,
+		BucketSize:    32,
+		HashFinalizer: MurmurFinalizer,
 	}
 }
 
 type entry[K Hashable[K], V any] struct {
 	key   K
 	value V
+	gen   uint64 // generation of the last insertion or update, see RangeSince
+}
+
+type Pair[K, V any] struct {
+	Key   K
+	Value V
 }
 
 type Map[K Hashable[K], V any] struct {
+	// stats comes first so that its atomically updated counters are 64-bit aligned on 32-bit platforms
+	stats     Stats
 	config    Config
 	entries   []*entry[K, V]
 	neighbors []uint32
+	present   []uint64
+	overflow  []*entry[K, V]
 	size, n   int
+
+	// tomb is the entry marking the slots of deleted entries, if Config.UseTombstones is set
+	tomb  *entry[K, V]
+	tombs int
+
+	// pins holds the pinned keys, allocated by the first call to Pin
+	pins *Map[K, struct{}]
+
+	// alloc is Config.Allocator, if it suits the types of the map
+	alloc Allocator[K, V]
+
+	// gen is the generation of the last insertion or update
+	gen uint64
+
+	// rehashes counts the rehashes, which move every entry and so invalidate cursors
+	rehashes uint64
 }
 
+// New creates a map from the given config, rounding Size up to a power of two (capped at MaxSize).
+// It panics if Allocator does not provide entries of the map type.
 func New[K Hashable[K], V any](c Config) *Map[K, V] {
-	return &Map[K, V]{
+	alloc, err := allocatorOf[K, V](c)
+	if err != nil {
+		panic(err)
+	}
+
+	c.Size = roundSize(c.Size)
+	m := &Map[K, V]{
 		config:    c,
 		entries:   make([]*entry[K, V], c.Size),
 		neighbors: make([]uint32, c.Size),
+		present:   make([]uint64, presentWords(c.Size)),
 		size:      c.Size,
 		n:         0,
+		alloc:     alloc,
+	}
+	if c.UseTombstones {
+		m.tomb = &entry[K, V]{}
+	}
+	return m
+}
+
+// NewE is like New, but validates the config first.
+func NewE[K Hashable[K], V any](c Config) (*Map[K, V], error) {
+	if err := c.validate(); err != nil {
+		return nil, err
 	}
+	if _, err := allocatorOf[K, V](c); err != nil {
+		return nil, err
+	}
+	return New[K, V](c), nil
+}
+
+func roundSize(size int) int {
+	if size <= 1 {
+		return size
+	}
+
+	rounded := uint64(1) << bits.Len64(uint64(size-1))
+	return int(min(rounded, MaxSize))
+}
+
+// Config returns a copy of the config of the map, reflecting the current Size.
+func (m *Map[_, _]) Config() Config {
+	return m.config
 }
 
 func zeroValue[V any]() V {
@@ -51,20 +262,136 @@ func zeroValue[V any]() V {
 func (m *Map[K, V]) Get(key K) (V, bool) {
 	hash := m.hashKey(key)
 
-	if e := m.findEntry(hash, key); e >= 0 {
-		return m.entries[e].value, true
+	if e := m.lookup(hash, key); e != nil {
+		m.countGet(true)
+		return (*e).value, true
 	}
+	m.countGet(false)
 	return zeroValue[V](), false
 }
 
+// GetBudgeted is like Get, but compares at most maxProbes stored keys with key, overflow set included,
+// bounding the time of the lookup. If the budget runs out before key is either found
+// or known to be absent, it reports exhausted, leaving the caller to treat the lookup
+// as a miss or to retry it later. A negative budget is unbounded.
+func (m *Map[K, V]) GetBudgeted(key K, maxProbes int) (value V, found, exhausted bool) {
+	hash := m.hashKey(key)
+
+	probes := 0
+	for nb, i := m.neighbors[hash], int(hash); nb != 0; nb, i = nb<<1, i+1 {
+		if nb&(1<<31) == 0 {
+			continue
+		}
+
+		if probes == maxProbes {
+			m.countGet(false)
+			return zeroValue[V](), false, true
+		}
+		probes++
+
+		if e := m.entries[mod(i, m.size)]; e != m.tomb && e.key.Equals(key) {
+			m.countGet(true)
+			return e.value, true, false
+		}
+	}
+
+	for _, e := range m.overflow {
+		if probes == maxProbes {
+			m.countGet(false)
+			return zeroValue[V](), false, true
+		}
+		probes++
+
+		if e.key.Equals(key) {
+			m.countGet(true)
+			return e.value, true, false
+		}
+	}
+	m.countGet(false)
+	return zeroValue[V](), false, false
+}
+
+// GetMany looks up each of the given keys, returning their values
+// and whether they were found, in the same order.
+func (m *Map[K, V]) GetMany(keys []K) ([]V, []bool) {
+	values, found := make([]V, len(keys)), make([]bool, len(keys))
+	m.GetBatchInto(keys, values, found)
+	return values, found
+}
+
+// GetBatchInto is like GetMany, but stores the results into the given slices,
+// which must have the same length as keys. It does not allocate.
+func (m *Map[K, V]) GetBatchInto(keys []K, values []V, found []bool) {
+	if len(values) != len(keys) || len(found) != len(keys) {
+		panic(fmt.Sprintf("hopmap: GetBatchInto got %d keys, but %d values and %d found slots", len(keys), len(values), len(found)))
+	}
+
+	for i, k := range keys {
+		values[i], found[i] = m.Get(k)
+	}
+}
+
+// GetPointer returns a pointer to the value stored under key.
+// The pointer remains valid until the key is deleted.
+func (m *Map[K, V]) GetPointer(key K) (*V, bool) {
+	hash := m.hashKey(key)
+
+	if e := m.lookup(hash, key); e != nil {
+		m.countGet(true)
+		return &(*e).value, true
+	}
+	m.countGet(false)
+	return nil, false
+}
+
+// GetWithHook is like Get, but invokes onHit with a pointer to the stored value
+// when the key is found, allowing in-place updates (e.g. recency metadata)
+// without a second lookup.
+func (m *Map[K, V]) GetWithHook(key K, onHit func(*V)) (V, bool) {
+	hash := m.hashKey(key)
+
+	if e := m.lookup(hash, key); e != nil {
+		m.countGet(true)
+		onHit(&(*e).value)
+		return (*e).value, true
+	}
+	m.countGet(false)
+	return zeroValue[V](), false
+}
+
+// lookup returns the slot holding key, either in the table or in the overflow set, or nil.
+func (m *Map[K, V]) lookup(hash uint32, key K) **entry[K, V] {
+	if e := m.findEntry(hash, key); e >= 0 {
+		return &m.entries[e]
+	}
+	if o := m.findOverflow(key); o >= 0 {
+		return &m.overflow[o]
+	}
+	return nil
+}
+
+// findEntry returns the slot of key among the neighbors of bucket hash, or -1.
+// Tombstones keep their neighbor bit, so they are skipped rather than ending the probe,
+// and the keys placed past them are still found. Empty slots never have their bit set.
 func (m *Map[K, V]) findEntry(hash uint32, key K) int {
 	neighbors := m.neighbors[hash]
 
+	// fast path: most keys sit in their home slot
+	if neighbors&(1<<31) != 0 {
+		if e := m.entries[hash]; e != m.tomb && e.key.Equals(key) {
+			return int(hash)
+		}
+		neighbors &^= 1 << 31
+	}
+	return m.probeEntry(hash, neighbors, key)
+}
+
+func (m *Map[K, V]) probeEntry(hash, neighbors uint32, key K) int {
 	zeros := bits.LeadingZeros32(neighbors)
 	i := mod(int(hash)+zeros, m.size)
 
 	for neighbors != 0 {
-		if e := m.entries[i]; e.key.Equals(key) {
+		if e := m.entries[i]; e != m.tomb && e.key.Equals(key) {
 			return int(i)
 		}
 
@@ -76,7 +403,8 @@ func (m *Map[K, V]) findEntry(hash uint32, key K) int {
 }
 
 func (m *Map[K, V]) hashKey(key K) uint32 {
-	return key.HashCode() % uint32(m.size)
+	h := m.config.finalize(key.HashCode() ^ m.config.Seed)
+	return h % uint32(m.size)
 }
 
 func (m *Map[K, V]) nextHash(hash uint32) uint32 {
@@ -95,15 +423,269 @@ func mod(n, m int) int {
 	return res
 }
 
+// Put stores value under key, reporting whether it succeeded.
+// Keys already in the map are handled according to Config.OnDuplicate.
 func (m *Map[K, V]) Put(key K, value V) bool {
+	return m.TryPut(key, value) == nil
+}
+
+// TryPut is like Put, but reports ErrTableFull when the key cannot be placed,
+// and ErrDuplicateKey when the key is present and Config.OnDuplicate is FailOnDuplicate.
+func (m *Map[K, V]) TryPut(key K, value V) error {
 	hash := m.hashKey(key)
 
-	if e := m.findEntry(hash, key); e >= 0 {
-		m.entries[e].value = value
+	if e := m.lookup(hash, key); e != nil {
+		return m.putExisting(e, value)
+	}
+	return m.putNew(hash, key, value)
+}
+
+// putExisting stores value into the entry of a key already in the map, according to Config.OnDuplicate.
+func (m *Map[K, V]) putExisting(e **entry[K, V], value V) error {
+	switch m.config.OnDuplicate {
+	case KeepExisting:
+	case FailOnDuplicate:
+		m.countPut(false)
+		return ErrDuplicateKey
+	default:
+		m.overwrite(e, value)
+	}
+	m.countPut(true)
+	return nil
+}
+
+// PutUnique inserts key only if it is not in the map yet, reporting ErrDuplicateKey otherwise.
+// Like TryPut, it reports ErrTableFull when the key cannot be placed.
+func (m *Map[K, V]) PutUnique(key K, value V) error {
+	hash := m.hashKey(key)
+
+	if m.lookup(hash, key) != nil {
+		m.countPut(false)
+		return ErrDuplicateKey
+	}
+	return m.putNew(hash, key, value)
+}
+
+// PutUntilLoad stores the pairs in order, stopping before a new key would raise the load factor above maxLoad,
+// or at the first pair which cannot be stored. It returns how many pairs were stored,
+// so that pairs[n:] are left to the caller. Keys already in the map are handled according to
+// Config.OnDuplicate, and never raise the load. The map never grows.
+func (m *Map[K, V]) PutUntilLoad(pairs []Pair[K, V], maxLoad float64) int {
+	for i, p := range pairs {
+		hash := m.hashKey(p.Key)
+
+		if e := m.lookup(hash, p.Key); e != nil {
+			if m.putExisting(e, p.Value) != nil {
+				return i
+			}
+			continue
+		}
+
+		if float64(m.n+1)/float64(m.size) > maxLoad {
+			return i
+		}
+		if e := m.newEntry(p.Key, p.Value); !m.insertHashed(hash, e) {
+			m.freeEntry(e)
+			return i
+		}
+		m.countPut(true)
+	}
+	return len(pairs)
+}
+
+// Ingest stores the pairs received from ch until it is closed, and returns how many new keys were inserted.
+// Pairs whose key is already in the map are handled according to Config.OnDuplicate if overwrite is set,
+// and are skipped otherwise. Pairs which cannot be stored are dropped.
+func (m *Map[K, V]) Ingest(ch <-chan Pair[K, V], overwrite bool) int {
+	inserted := 0
+	for p := range ch {
+		hash := m.hashKey(p.Key)
+
+		if e := m.lookup(hash, p.Key); e != nil {
+			if overwrite {
+				m.putExisting(e, p.Value)
+			}
+			continue
+		}
+
+		if m.putNew(hash, p.Key, p.Value) == nil {
+			inserted++
+		}
+	}
+	return inserted
+}
+
+// Intern returns the key stored in the map which is equal to key, inserting key
+// with a zero value if absent, so that callers can share a single canonical instance.
+// If key is absent and cannot be inserted, it is returned as is.
+func (m *Map[K, V]) Intern(key K) K {
+	hash := m.hashKey(key)
+
+	if e := m.lookup(hash, key); e != nil {
+		return (*e).key
+	}
+	m.putNew(hash, key, zeroValue[V]())
+	return key
+}
+
+// GetOrCompute returns the value of key if present, otherwise it stores and returns the value
+// produced by factory. If factory fails or panics, nothing is inserted. If factory inserts key itself,
+// the produced value is handled according to Config.OnDuplicate, and the value stored in the end is returned.
+func (m *Map[K, V]) GetOrCompute(key K, factory func(K) (V, error)) (V, error) {
+	hash := m.hashKey(key)
+
+	if e := m.lookup(hash, key); e != nil {
+		m.countGet(true)
+		return (*e).value, nil
+	}
+	m.countGet(false)
+
+	// the map is not touched until factory returns
+	value, err := factory(key)
+	if err != nil {
+		return zeroValue[V](), err
+	}
+
+	// factory may have resized the map, moving the home bucket of key, or even inserted key itself
+	hash = m.hashKey(key)
+	if e := m.lookup(hash, key); e != nil {
+		if err := m.putExisting(e, value); err != nil {
+			return zeroValue[V](), err
+		}
+		return (*e).value, nil
+	}
+
+	if err := m.putNew(hash, key, value); err != nil {
+		return zeroValue[V](), err
+	}
+	return value, nil
+}
+
+// EnsureSlot returns a pointer to the value of key, inserting key with the zero value first if absent,
+// so that the key can be placed before its value is known. It also reports whether key was inserted,
+// and returns a nil pointer if it could not be. As with GetPointer, the pointer stays valid
+// while key is in the map and is not overwritten under CopyOnOverwrite.
+func (m *Map[K, V]) EnsureSlot(key K) (*V, bool) {
+	hash := m.hashKey(key)
+
+	if e := m.lookup(hash, key); e != nil {
+		return &(*e).value, false
+	}
+
+	e := m.newEntry(key, zeroValue[V]())
+	if m.putEntry(hash, e) != nil {
+		return nil, false
+	}
+	return &e.value, true
+}
+
+// Accumulate stores combine(current, delta) under key if present, or delta otherwise.
+func (m *Map[K, V]) Accumulate(key K, delta V, combine func(cur, delta V) V) bool {
+	hash := m.hashKey(key)
+
+	if e := m.lookup(hash, key); e != nil {
+		m.overwrite(e, combine((*e).value, delta))
+		m.countPut(true)
+		return true
+	}
+	return m.putNew(hash, key, delta) == nil
+}
+
+func (m *Map[K, V]) overwrite(e **entry[K, V], value V) {
+	if m.config.CopyOnOverwrite {
+		*e = m.newEntry((*e).key, value)
+	} else {
+		(*e).value, (*e).gen = value, m.stamp()
+	}
+}
+
+// putNew stores a key known not to be in the map, whose home bucket is hash,
+// so that new keys are hashed only once on the common path.
+func (m *Map[K, V]) putNew(hash uint32, key K, value V) error {
+	return m.putEntry(hash, m.newEntry(key, value))
+}
+
+// putEntry places an entry whose key is known not to be in the map and whose home bucket is hash,
+// growing the map, or spilling into the overflow set, as configured.
+func (m *Map[K, V]) putEntry(hash uint32, e *entry[K, V]) error {
+	if m.shouldGrow() && m.grow() {
+		hash = m.hashKey(e.key)
+	}
+
+	for !m.insertHashed(hash, e) {
+		if m.tombs > 0 {
+			m.purgeTombstones()
+			continue
+		}
+
+		if m.config.AllowOverflow {
+			m.appendOverflow(e)
+			break
+		}
+
+		// Growing cannot make room for a key whose neighborhood is full of keys with its hash code.
+		// Otherwise, each grow spreads colliding keys over more buckets, until growLimit.
+		if m.config.AutoResize && !m.crowdedBy(hash, e.key) && m.grow() {
+			hash = m.hashKey(e.key)
+			continue
+		}
+
+		m.freeEntry(e)
+		m.countPut(false)
+		return ErrTableFull
+	}
+	m.countPut(true)
+	return nil
+}
+
+// crowdedBy reports whether all the slots of the neighborhood of hash hold keys
+// with the same hash code as key, which share their home bucket at any size.
+func (m *Map[K, V]) crowdedBy(hash uint32, key K) bool {
+	if bits.OnesCount32(m.neighbors[hash]) < m.config.BucketSize {
+		return false
+	}
+
+	code := key.HashCode()
+	for off := 0; off < m.config.BucketSize; off++ {
+		if m.entries[mod(int(hash)+off, m.size)].key.HashCode() != code {
+			return false
+		}
+	}
+	return true
+}
+
+// insert places an entry whose key is known not to be in the map.
+func (m *Map[K, V]) insert(e *entry[K, V]) bool {
+	return m.insertHashed(m.hashKey(e.key), e)
+}
+
+// insertHashed is like insert, for an entry whose home bucket is already known.
+func (m *Map[K, V]) insertHashed(hash uint32, e *entry[K, V]) bool {
+	if m.config.DebugCheckKeys {
+		checkKey(e.key)
+	}
+
+	if m.reclaimTombstone(hash, e) {
 		return true
 	}
 
-	emptySlot := m.findEmptySlot(hash)
+	if m.config.DoubleHashing {
+		if j := m.probeNeighborhood(hash, e.key); j >= 0 {
+			m.entries[j] = e
+			m.markPresent(j)
+			m.setNeighbor(int(hash), mod(j-int(hash), m.size))
+			m.n++
+			return true
+		}
+	}
+
+	var emptySlot int
+	if m.config.ProbeStrategy == Bidirectional {
+		emptySlot = m.findEmptySlotBidirectional(hash)
+	} else {
+		emptySlot = m.findEmptySlot(hash)
+	}
+
 	if emptySlot < 0 || m.neighbors[emptySlot] == allBitSet {
 		return false
 	}
@@ -114,16 +696,118 @@ func (m *Map[K, V]) Put(key K, value V) bool {
 		return false
 	}
 
-	m.entries[j] = &entry[K, V]{key, value}
+	m.entries[j] = e
+	m.markPresent(j)
 	m.neighbors[i] |= 1 << (31 - dist)
 
 	m.n++
 	return true
 }
 
+func (m *Map[_, _]) shouldGrow() bool {
+	return m.config.AutoResize && m.config.MaxLoad > 0 &&
+		float64(m.n+1)/float64(m.size) > m.config.MaxLoad
+}
+
+func (m *Map[_, _]) shouldShrink() bool {
+	if !m.config.AutoResize || m.config.MinLoad <= 0 || m.size <= 1 ||
+		float64(m.n)/float64(m.size) >= m.config.MinLoad {
+		return false
+	}
+
+	maxLoad := m.config.MaxLoad
+	if maxLoad <= 0 {
+		maxLoad = 1
+	}
+	return m.config.EagerResize || float64(m.n)/float64(m.size/2) <= (m.config.MinLoad+maxLoad)/2
+}
+
+// grow doubles the size of the map until all the entries fit, up to growLimit.
+func (m *Map[_, _]) grow() bool {
+	limit := growLimit(m.config, m.n)
+	for size := uint64(m.size); ; {
+		size = min(2*size, MaxSize)
+		if size <= uint64(m.size) || size > limit {
+			return false
+		}
+		if m.rehash(int(size)) {
+			return true
+		}
+	}
+}
+
+// Resize rehashes all the entries into tables of the given size, rounded up as in New.
+func (m *Map[K, V]) Resize(size int) error {
+	if size <= 0 || uint64(size) > MaxSize {
+		return fmt.Errorf("%w: size %d must be in [1, %d]", ErrInvalidConfig, size, uint64(MaxSize))
+	}
+	size = roundSize(size)
+	if size < m.n {
+		return fmt.Errorf("%w: %d entries do not fit into size %d", ErrResizeTooSmall, m.n, size)
+	}
+	if !m.rehash(size) {
+		return fmt.Errorf("%w: unable to place all entries into size %d", ErrResizeTooSmall, size)
+	}
+	return nil
+}
+
+// SetBucketSize rehashes all the entries into neighborhoods of the given size, keeping the size
+// of the table, so that the map can be tuned without being rebuilt. Entries of the overflow set
+// are placed into the table if they now fit. If the entries of the table cannot all be placed,
+// the map is left unchanged and ErrResizeTooSmall is returned.
+func (m *Map[K, V]) SetBucketSize(bucketSize int) error {
+	if bucketSize <= 0 || bucketSize > 32 {
+		return fmt.Errorf("%w: bucket size %d must be in [1, 32]", ErrInvalidConfig, bucketSize)
+	}
+
+	old := m.config.BucketSize
+	m.config.BucketSize = bucketSize
+	if !m.rehash(m.size) {
+		m.config.BucketSize = old
+		return fmt.Errorf("%w: unable to place all entries with bucket size %d", ErrResizeTooSmall, bucketSize)
+	}
+	return nil
+}
+
+// rehash moves all the entries to freshly allocated tables of the given size.
+// Entries of the overflow set are moved to the table when possible.
+// On failure, the map is left untouched.
+func (m *Map[K, V]) rehash(size int) bool {
+	entries, neighbors, present, overflow, oldSize, n, tombs := m.entries, m.neighbors, m.present, m.overflow, m.size, m.n, m.tombs
+
+	m.entries = make([]*entry[K, V], size)
+	m.neighbors = make([]uint32, size)
+	m.present = make([]uint64, presentWords(size))
+	m.overflow = nil
+	m.size = size
+	m.n = 0
+	m.tombs = 0
+
+	for _, e := range entries {
+		if e != nil && e != m.tomb && !m.insert(e) {
+			m.entries, m.neighbors, m.present, m.overflow, m.size, m.n, m.tombs = entries, neighbors, present, overflow, oldSize, n, tombs
+			return false
+		}
+	}
+
+	for _, e := range overflow {
+		if !m.insert(e) {
+			m.appendOverflow(e)
+		}
+	}
+	m.config.Size = size
+	m.rehashes++
+	m.countResize()
+	return true
+}
+
 func (m *Map[K, V]) shiftEmptySlotTo(i, j int) (int, int) {
 	dist := mod(j-i, m.size)
-	for dist >= int(m.config.BucketSize) {
+	for n := 0; dist >= int(m.config.BucketSize); n++ {
+		if m.config.MaxReshift > 0 && n == m.config.MaxReshift {
+			return -1, dist
+		}
+
 		j = m.reshift(j)
 		if j < 0 {
 			return j, dist
@@ -134,17 +818,14 @@ func (m *Map[K, V]) shiftEmptySlotTo(i, j int) (int, int) {
 }
 
 func (m *Map[K, V]) findEmptySlot(startHash uint32) int {
-	if m.entries[startHash] == nil {
-		return int(startHash)
+	start, limit := int(startHash), m.config.probeLimit(m.size)
+	if j := m.nextEmpty(start, min(start+limit, m.size)); j >= 0 {
+		return j
 	}
 
-	hash := m.nextHash(startHash)
-	for hash != startHash && m.entries[hash] != nil {
-		hash = m.nextHash(hash)
-	}
-
-	if hash != startHash {
-		return int(hash)
+	// wrap around
+	if j := m.nextEmpty(0, min(start+limit-m.size, start)); j >= 0 {
+		return j
 	}
 	return -1
 }
@@ -154,6 +835,9 @@ func (m *Map[_, _]) reshift(j int) int {
 	if k >= 0 {
 		m.entries[j] = m.entries[k]
 		m.entries[k] = nil
+		m.markPresent(j)
+		m.markEmpty(k)
+		m.countReshift(k, j)
 	}
 	return k
 }
@@ -186,24 +870,70 @@ func (m *Map[_, _]) setNeighbor(entry int, neighbor int) {
 	m.neighbors[entry] |= uint32(1 << (31 - neighbor))
 }
 
+// Delete removes key from the map, returning its value.
+// No other entry is moved unless the map shrinks (see Config.MinLoad), and even then
+// pointers obtained through GetPointer for other keys stay valid.
 func (m *Map[K, V]) Delete(key K) (V, bool) {
 	hash := m.hashKey(key)
 
+	var value V
 	if e := m.findEntry(hash, key); e >= 0 {
-		m.clearNeighbor(int(hash), mod(e-int(hash), m.size))
+		value = m.deleteAt(hash, e)
+	} else if o := m.findOverflow(key); o >= 0 {
+		value = m.deleteOverflow(o)
+	} else {
+		return zeroValue[V](), false
+	}
 
-		value := m.entries[e].value
-		m.resetEntry(m.entries[e])
-		m.entries[e] = nil
-		m.n--
-		return value, true
+	if m.shouldShrink() {
+		m.rehash(m.size / 2)
 	}
-	return zeroValue[V](), false
+	return value, true
+}
+
+// deleteAt removes the entry at slot e, whose home bucket is hash.
+func (m *Map[K, V]) deleteAt(hash uint32, e int) V {
+	m.countDelete()
+
+	value := m.entries[e].value
+	m.unpinDeleted(m.entries[e].key)
+	m.freeEntry(m.entries[e])
+	m.n--
+
+	if m.tomb != nil {
+		m.entries[e] = m.tomb
+		m.tombs++
+		m.purgeTombstonesIfNeeded()
+		return value
+	}
+
+	m.clearNeighbor(int(hash), mod(e-int(hash), m.size))
+	m.entries[e] = nil
+	m.markEmpty(e)
+	return value
 }
 
 func (m *Map[K, V]) resetEntry(e *entry[K, V]) {
-	e.key = zeroValue[K]()
-	e.value = zeroValue[V]()
+	*e = entry[K, V]{}
+}
+
+// Clear removes all the entries, keeping the current size.
+func (m *Map[K, V]) Clear() {
+	if m.alloc != nil {
+		m.forEach(func(e *entry[K, V]) bool {
+			m.freeEntry(e)
+			return true
+		})
+	}
+
+	clear(m.entries)
+	clear(m.neighbors)
+	clear(m.present)
+	clear(m.overflow)
+	m.overflow = m.overflow[:0]
+	m.n = 0
+	m.tombs = 0
+	m.pins = nil
 }
 
 func (m *Map[_, _]) Len() int {
@@ -217,3 +947,488 @@ func (m *Map[_, _]) Size() int {
 func (m *Map[_, _]) Load() float64 {
 	return float64(m.Len()) / float64(m.Size())
 }
+
+// CanFit reports whether additional insertions of new keys are guaranteed to succeed
+// without resizing the table nor spilling into the overflow set. The estimate is conservative:
+// it holds when every run of BucketSize consecutive slots has at least additional empty slots,
+// since each insertion then takes a slot within the neighborhood of its key, removing at most
+// one empty slot from every run. Overwrites of existing keys need no room. It runs in O(Size) time.
+func (m *Map[_, _]) CanFit(additional int) bool {
+	if additional <= 0 {
+		return true
+	}
+	if m.config.AutoResize && m.config.MaxLoad > 0 &&
+		float64(m.n+additional)/float64(m.size) > m.config.MaxLoad {
+		return false
+	}
+
+	window := min(m.config.BucketSize, m.size)
+	empty := 0
+	for j := 0; j < window; j++ {
+		if !m.isPresent(j) {
+			empty++
+		}
+	}
+
+	for j := 0; j < m.size && empty >= additional; j++ {
+		// slide the run [j, j+window) forward by one slot
+		if !m.isPresent(j) {
+			empty--
+		}
+		if !m.isPresent(mod(j+window, m.size)) {
+			empty++
+		}
+	}
+	return empty >= additional
+}
+
+// cacheLineSize is a common cache line size, used as the stride of Prewarm.
+const cacheLineSize = 64
+
+// prewarmSink keeps the loads of Prewarm from being optimized away.
+// It is updated atomically, since Prewarm may run concurrently under a read lock.
+var prewarmSink uint32
+
+// Prewarm touches the entries and neighbors slices sequentially, one cache line at a time,
+// to pull them into the CPU caches before a latency-sensitive burst of lookups,
+// such as right after loading or resizing the map. It is only a best-effort hint:
+// caches may be too small to hold the table, and lines may be evicted at any time.
+func (m *Map[K, V]) Prewarm() {
+	var sum uint32
+	for i := 0; i < len(m.neighbors); i += cacheLineSize / 4 {
+		sum += m.neighbors[i]
+	}
+	for i := 0; i < len(m.entries); i += cacheLineSize / int(unsafe.Sizeof(m.entries[0])) {
+		if m.entries[i] != nil {
+			sum++
+		}
+	}
+	atomic.AddUint32(&prewarmSink, sum)
+}
+
+func (m *Map[K, V]) Clone() *Map[K, V] {
+	c := &Map[K, V]{
+		config:    m.config,
+		entries:   make([]*entry[K, V], m.size),
+		neighbors: make([]uint32, m.size),
+		present:   make([]uint64, len(m.present)),
+		size:      m.size,
+		n:         m.n,
+		stats:     m.Stats(),
+		tombs:     m.tombs,
+		alloc:     m.alloc,
+		gen:       m.gen,
+	}
+	copy(c.neighbors, m.neighbors)
+	copy(c.present, m.present)
+
+	if m.tomb != nil {
+		c.tomb = &entry[K, V]{}
+	}
+	if m.pins != nil {
+		c.pins = m.pins.Clone()
+	}
+
+	for i, e := range m.entries {
+		switch {
+		case e == nil:
+		case e == m.tomb:
+			c.entries[i] = c.tomb
+		default:
+			c.entries[i] = c.copyEntry(e)
+		}
+	}
+
+	if len(m.overflow) > 0 {
+		c.overflow = make([]*entry[K, V], len(m.overflow), cap(m.overflow))
+		for i, e := range m.overflow {
+			c.overflow[i] = c.copyEntry(e)
+		}
+	}
+	return c
+}
+
+// CloneCompact is like Clone, but sizes the copy to the smallest power of two
+// which holds the live entries at a load factor of at most 0.5.
+// It returns ErrTableFull if the entries cannot be placed at any size.
+func (m *Map[K, V]) CloneCompact() (*Map[K, V], error) {
+	size := 1
+	for size < 2*m.n {
+		size <<= 1
+	}
+
+	for {
+		config := m.config
+		config.Size = size
+
+		c := New[K, V](config)
+		e := m.copyTo(c)
+		if e == nil {
+			if m.pins != nil {
+				c.pins = m.pins.Clone()
+			}
+			return c, nil
+		}
+
+		crowded := c.crowdedBy(c.hashKey(e.key), e.key)
+
+		// hand the copies back to the allocator before retrying
+		c.Clear()
+		if uint64(size) >= MaxSize || crowded {
+			return nil, fmt.Errorf("%w: unable to place all entries at any size", ErrTableFull)
+		}
+		size = int(min(2*uint64(size), MaxSize))
+	}
+}
+
+// copyTo inserts copies of all the entries of m into c, using the overflow set of c if allowed.
+// It returns the first entry which could not be placed, or nil.
+func (m *Map[K, V]) copyTo(c *Map[K, V]) *entry[K, V] {
+	c.gen = m.gen
+
+	var failed *entry[K, V]
+	m.forEach(func(e *entry[K, V]) bool {
+		ce := c.copyEntry(e)
+		switch {
+		case c.insert(ce):
+		case c.config.AllowOverflow:
+			c.appendOverflow(ce)
+		default:
+			c.freeEntry(ce)
+			failed = e
+			return false
+		}
+		return true
+	})
+	return failed
+}
+
+// ContainsAll reports whether all the given keys are in the map, stopping at the first missing one.
+// It is true for an empty slice of keys.
+func (m *Map[K, V]) ContainsAll(keys []K) bool {
+	for _, k := range keys {
+		if m.lookup(m.hashKey(k), k) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsAny reports whether any of the given keys is in the map, stopping at the first present one.
+// It is false for an empty slice of keys.
+func (m *Map[K, V]) ContainsAny(keys []K) bool {
+	for _, k := range keys {
+		if m.lookup(m.hashKey(k), k) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsValue reports whether any entry holds a value equal to v according to eq.
+// It scans the whole table, so it runs in O(Size) time.
+func (m *Map[K, V]) ContainsValue(v V, eq func(V, V) bool) bool {
+	_, found := m.KeyOf(v, eq)
+	return found
+}
+
+// KeyOf returns a key whose value is equal to v according to eq.
+// If several keys match, which one is returned is unspecified.
+func (m *Map[K, V]) KeyOf(v V, eq func(V, V) bool) (K, bool) {
+	var match *entry[K, V]
+	m.forEach(func(e *entry[K, V]) bool {
+		if eq(e.value, v) {
+			match = e
+		}
+		return match == nil
+	})
+
+	if match == nil {
+		return zeroValue[K](), false
+	}
+	return match.key, true
+}
+
+// OccupiedSlots returns the indices of the occupied slots of the table, in ascending order,
+// which can be resolved through ValueAt. Entries of the overflow set have no slot.
+// Slots are invalidated by any mutation of the map, since insertions and resizes may move entries.
+func (m *Map[K, V]) OccupiedSlots() []uint32 {
+	slots := make([]uint32, 0, m.n-len(m.overflow))
+	m.forEachPresent(func(j int) bool {
+		if m.entries[j] != m.tomb {
+			slots = append(slots, uint32(j))
+		}
+		return true
+	})
+	return slots
+}
+
+// SlotOf returns the slot holding key, to index data kept outside the map alongside the table.
+// It returns false if key is not in the map, or lies in the overflow set.
+// As with OccupiedSlots, the slot is only valid until the next mutation of the map.
+func (m *Map[K, V]) SlotOf(key K) (int, bool) {
+	j := m.findEntry(m.hashKey(key), key)
+	return j, j >= 0
+}
+
+// ValueAt returns the value stored at the given slot, if it is occupied.
+func (m *Map[K, V]) ValueAt(slot uint32) (V, bool) {
+	if uint64(slot) >= uint64(m.size) || m.entries[slot] == nil || m.entries[slot] == m.tomb {
+		return zeroValue[V](), false
+	}
+	return m.entries[slot].value, true
+}
+
+// Range calls fn for each entry of the map, stopping as soon as fn returns false.
+func (m *Map[K, V]) Range(fn func(K, V) bool) {
+	m.forEach(func(e *entry[K, V]) bool {
+		return fn(e.key, e.value)
+	})
+}
+
+func (m *Map[K, V]) RangeKeys(fn func(K) bool) {
+	m.forEach(func(e *entry[K, V]) bool {
+		return fn(e.key)
+	})
+}
+
+func (m *Map[K, V]) RangeValues(fn func(V) bool) {
+	m.forEach(func(e *entry[K, V]) bool {
+		return fn(e.value)
+	})
+}
+
+// RangeReverse is like Range, but visits the slots of the table from the last one to the first,
+// followed by the entries of the overflow set, last first.
+func (m *Map[K, V]) RangeReverse(fn func(K, V) bool) {
+	for w := len(m.present) - 1; w >= 0; w-- {
+		for word := m.present[w]; word != 0; {
+			b := 63 - bits.LeadingZeros64(word)
+			word &^= 1 << b
+
+			if e := m.entries[w<<6+b]; e != m.tomb && !fn(e.key, e.value) {
+				return
+			}
+		}
+	}
+
+	for i := len(m.overflow) - 1; i >= 0; i-- {
+		if e := m.overflow[i]; !fn(e.key, e.value) {
+			return
+		}
+	}
+}
+
+// RangeOrdered is like Range, but visits entries by ascending home bucket and,
+// within a bucket, by ascending offset from it. Entries of the overflow set come last.
+func (m *Map[K, V]) RangeOrdered(fn func(K, V) bool) {
+	for i, nb := range m.neighbors {
+		for j := i; nb != 0; j, nb = j+1, nb<<1 {
+			if nb&(1<<31) == 0 {
+				continue
+			}
+
+			if e := m.entries[mod(j, m.size)]; e != m.tomb && !fn(e.key, e.value) {
+				return
+			}
+		}
+	}
+
+	for _, e := range m.overflow {
+		if !fn(e.key, e.value) {
+			return
+		}
+	}
+}
+
+// RangeBuckets is like RangeOrdered, but only visits the entries homed in buckets [start, end),
+// including those of the overflow set. Since every entry has exactly one home bucket,
+// disjoint ranges can be processed concurrently, as long as the map is not modified.
+func (m *Map[K, V]) RangeBuckets(start, end uint32, fn func(K, V) bool) {
+	if start > end || uint64(end) > uint64(m.size) {
+		panic(fmt.Sprintf("hopmap: bucket range [%d, %d) out of range for size %d", start, end, m.size))
+	}
+
+	for i := int(start); i < int(end); i++ {
+		for j, nb := i, m.neighbors[i]; nb != 0; j, nb = j+1, nb<<1 {
+			if nb&(1<<31) == 0 {
+				continue
+			}
+
+			if e := m.entries[mod(j, m.size)]; e != m.tomb && !fn(e.key, e.value) {
+				return
+			}
+		}
+	}
+
+	for _, e := range m.overflow {
+		if h := m.hashKey(e.key); h >= start && h < end && !fn(e.key, e.value) {
+			return
+		}
+	}
+}
+
+// forEach calls fn on the entries of the table and then on those of the overflow set,
+// stopping as soon as fn returns false.
+func (m *Map[K, V]) forEach(fn func(*entry[K, V]) bool) {
+	stopped := false
+	m.forEachPresent(func(j int) bool {
+		if e := m.entries[j]; e != m.tomb && !fn(e) {
+			stopped = true
+		}
+		return !stopped
+	})
+	if stopped {
+		return
+	}
+
+	for _, e := range m.overflow {
+		if !fn(e) {
+			return
+		}
+	}
+}
+
+type Action int
+
+const (
+	Keep Action = iota
+	Delete
+	Stop
+)
+
+// RangeMut calls fn for each entry of the map, passing a pointer to the stored value
+// so that it can be updated in place. The returned Action tells whether to keep the entry,
+// delete it, or stop the iteration. Deleting never relocates other entries,
+// so the iteration visits every entry exactly once.
+func (m *Map[K, V]) RangeMut(fn func(K, *V) Action) {
+	for i, e := range m.entries {
+		if e == nil || e == m.tomb {
+			continue
+		}
+
+		switch fn(e.key, &e.value) {
+		case Delete:
+			m.deleteAt(m.hashKey(e.key), i)
+		case Stop:
+			return
+		}
+	}
+
+	// deleteOverflow moves the last entry into the removed position,
+	// so visiting backwards keeps every entry visited exactly once
+	for i := len(m.overflow) - 1; i >= 0; i-- {
+		e := m.overflow[i]
+
+		switch fn(e.key, &e.value) {
+		case Delete:
+			m.deleteOverflow(i)
+		case Stop:
+			return
+		}
+	}
+}
+
+// TransformValues replaces the value of each entry with the result of fn.
+// Entries are not moved, and values are overwritten as by Put, so CopyOnOverwrite is honored.
+func (m *Map[K, V]) TransformValues(fn func(K, V) V) {
+	m.forEachPresent(func(j int) bool {
+		if e := m.entries[j]; e != m.tomb {
+			m.overwrite(&m.entries[j], fn(e.key, e.value))
+		}
+		return true
+	})
+
+	for i, e := range m.overflow {
+		m.overwrite(&m.overflow[i], fn(e.key, e.value))
+	}
+}
+
+// UpdateWhere replaces the value of each entry satisfying pred with the result of update,
+// and returns how many entries have been updated. As with TransformValues, entries are not moved.
+func (m *Map[K, V]) UpdateWhere(pred func(K, V) bool, update func(V) V) int {
+	updated := 0
+	updateEntry := func(e **entry[K, V]) {
+		if pred((*e).key, (*e).value) {
+			m.overwrite(e, update((*e).value))
+			updated++
+		}
+	}
+
+	m.forEachPresent(func(j int) bool {
+		if m.entries[j] != m.tomb {
+			updateEntry(&m.entries[j])
+		}
+		return true
+	})
+
+	for i := range m.overflow {
+		updateEntry(&m.overflow[i])
+	}
+	return updated
+}
+
+// Drain returns a channel emitting every entry of the map, removing each one once it has been received,
+// and closed once the map is empty or ctx is done. Entries which were not received when ctx is done are
+// left in the map. The map is drained by a separate goroutine, so it must not be accessed until the channel
+// is closed: a consumer which stops early must cancel ctx and wait for the channel to be closed.
+func (m *Map[K, V]) Drain(ctx context.Context) <-chan Pair[K, V] {
+	ch := make(chan Pair[K, V])
+	go func() {
+		defer close(ch)
+
+		send := func(p Pair[K, V]) bool {
+			select {
+			case ch <- p:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for i, e := range m.entries {
+			if e == nil || e == m.tomb {
+				continue
+			}
+
+			if !send(Pair[K, V]{e.key, e.value}) {
+				return
+			}
+			// deleteAt resets the entry, and never relocates others
+			m.deleteAt(m.hashKey(e.key), i)
+		}
+
+		for len(m.overflow) > 0 {
+			e := m.overflow[len(m.overflow)-1]
+			if !send(Pair[K, V]{e.key, e.value}) {
+				return
+			}
+			m.deleteOverflow(len(m.overflow) - 1)
+		}
+	}()
+	return ch
+}
+
+// Partition splits the map into two new maps, holding the entries which satisfy pred and the remaining ones.
+// Both maps share the config of m.
+func (m *Map[K, V]) Partition(pred func(K, V) bool) (matched, rest *Map[K, V]) {
+	matched, rest = New[K, V](m.config), New[K, V](m.config)
+	m.forEach(func(e *entry[K, V]) bool {
+		if pred(e.key, e.value) {
+			matched.mustInsert(matched.newEntry(e.key, e.value))
+		} else {
+			rest.mustInsert(rest.newEntry(e.key, e.value))
+		}
+		return true
+	})
+	return matched, rest
+}
+
+// mustInsert is like insert, but grows the map until e can be placed.
+func (m *Map[K, V]) mustInsert(e *entry[K, V]) {
+	for !m.insert(e) {
+		if !m.grow() {
+			panic(ErrTableFull)
+		}
+	}
+}
@@ -0,0 +1,403 @@
+package hopmap
+
+import (
+	"math/rand"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type intKey uint32
+
+func (x intKey) Equals(y intKey) bool {
+	return x == y
+}
+
+func (x intKey) HashCode() uint32 {
+	return uint32(x)
+}
+
+func TestFindEntryFastPath(t *testing.T) {
+	m := New[intKey, int](Config{Size: 1 << 10, BucketSize: 32})
+
+	r := rand.New(rand.NewSource(1))
+	keys := make([]intKey, 0)
+	for len(keys) < 900 {
+		k := intKey(r.Intn(1 << 12))
+		if m.Put(k, 0) {
+			keys = append(keys, k)
+		}
+	}
+
+	for k := intKey(0); k < 1<<12; k++ {
+		hash := m.hashKey(k)
+		e := m.findEntry(hash, k)
+		require.Equal(t, m.probeEntry(hash, m.neighbors[hash], k), e)
+
+		if trace := m.TraceGet(k); e >= 0 {
+			require.Equal(t, uint32(e), trace[len(trace)-1])
+		}
+	}
+}
+
+func TestLargeSizeArithmetic(t *testing.T) {
+	if strconv.IntSize < 64 {
+		t.Skipf("sizes above 2^31 exceed MaxSize (%d) on 32-bit platforms", uint64(MaxSize))
+	}
+
+	// only the index arithmetic is exercised, without allocating the tables
+	var size uint64 = 3 << 30
+	m := &Map[intKey, int]{size: int(size)}
+
+	require.Equal(t, uint32(1<<31+7), m.hashKey(1<<31+7))
+	require.Equal(t, uint32(5), m.hashKey(intKey(size+5)))
+	require.Equal(t, uint32(0), m.nextHash(uint32(size-1)))
+	require.Equal(t, int(size-1), mod(-1, m.size))
+
+	m.size = MaxSize
+	require.Equal(t, uint32(0), m.hashKey(MaxSize))
+	require.Equal(t, uint32(0), m.nextHash(MaxSize-1))
+}
+
+func TestRepair(t *testing.T) {
+	m := New[intKey, int](Config{Size: 64, BucketSize: 8})
+	for i := 0; i < 40; i++ {
+		m.Put(intKey(i*3), i)
+	}
+	require.NoError(t, m.Validate())
+	require.Zero(t, m.Repair())
+
+	m.neighbors[m.hashKey(9)] ^= 1 << 31
+	require.ErrorIs(t, m.Validate(), ErrCorrupted)
+
+	require.Equal(t, 1, m.Repair())
+	require.NoError(t, m.Validate())
+
+	v, ok := m.Get(9)
+	require.True(t, ok)
+	require.Equal(t, 3, v)
+}
+
+func TestRepairMisplaced(t *testing.T) {
+	m := New[intKey, int](Config{Size: 64, BucketSize: 4})
+	m.Put(1, 1)
+
+	// move the entry out of the neighborhood of its home bucket
+	m.entries[10], m.entries[1] = m.entries[1], nil
+	require.ErrorIs(t, m.Validate(), ErrCorrupted)
+
+	require.Equal(t, 2, m.Repair())
+	require.NoError(t, m.Validate())
+	require.Equal(t, 1, m.Len())
+
+	v, ok := m.Get(1)
+	require.True(t, ok)
+	require.Equal(t, 1, v)
+}
+
+func TestOrphanedEntries(t *testing.T) {
+	m := New[intKey, int](Config{Size: 64, BucketSize: 8, UseTombstones: true})
+	for i := 0; i < 40; i++ {
+		m.Put(intKey(i*3), i)
+	}
+	m.Delete(0)
+	require.Empty(t, m.OrphanedEntries())
+
+	// clear the bit of an entry, and move another one out of its neighborhood
+	m.neighbors[m.hashKey(9)] &^= 1 << 31
+	j := m.nextEmpty(40, m.size)
+	require.Positive(t, j)
+	m.entries[j], m.entries[30] = m.entries[30], nil
+	m.markPresent(j)
+	m.markEmpty(30)
+	require.ElementsMatch(t, []intKey{9, 30}, m.OrphanedEntries())
+
+	m.Repair()
+	require.Empty(t, m.OrphanedEntries())
+	require.NoError(t, m.Validate())
+	require.Equal(t, 39, m.Len())
+	for i := 1; i < 40; i++ {
+		v, ok := m.Get(intKey(i * 3))
+		require.True(t, ok)
+		require.Equal(t, i, v)
+	}
+}
+
+func TestSeedDeterminism(t *testing.T) {
+	build := func(seed uint32) *Map[intKey, int] {
+		m := New[intKey, int](Config{Size: 1 << 10, BucketSize: 32, Seed: seed})
+
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < 900; i++ {
+			k := intKey(r.Intn(1 << 12))
+			if i%3 == 0 {
+				m.Delete(k)
+			} else {
+				m.Put(k, i)
+			}
+		}
+		return m
+	}
+
+	m1, m2 := build(42), build(42)
+	require.Equal(t, uint32(42), m1.Config().Seed)
+	require.Equal(t, m1.neighbors, m2.neighbors)
+	for i := range m1.entries {
+		require.Equal(t, m1.entries[i], m2.entries[i])
+	}
+
+	require.NotEqual(t, m1.neighbors, build(43).neighbors)
+}
+
+func TestOccupiedSlots(t *testing.T) {
+	m := New[intKey, int](Config{Size: 1 << 8, BucketSize: 8})
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 150; i++ {
+		k := intKey(r.Intn(1 << 10))
+		m.Put(k, int(k))
+	}
+
+	slots := m.OccupiedSlots()
+	require.Len(t, slots, m.Len())
+
+	occupied := make(map[uint32]bool)
+	for _, s := range slots {
+		occupied[s] = true
+
+		v, ok := m.ValueAt(s)
+		require.True(t, ok)
+		require.Equal(t, m.entries[s].value, v)
+		require.Equal(t, int(m.entries[s].key), v)
+	}
+
+	for i, e := range m.entries {
+		require.Equal(t, e != nil, occupied[uint32(i)])
+		if e == nil {
+			_, ok := m.ValueAt(uint32(i))
+			require.False(t, ok)
+		}
+	}
+
+	_, ok := m.ValueAt(uint32(m.size))
+	require.False(t, ok)
+}
+
+func TestSlotOf(t *testing.T) {
+	m := New[intKey, int](Config{Size: 1 << 8, BucketSize: 8, AllowOverflow: true})
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 150; i++ {
+		k := intKey(r.Intn(1 << 10))
+		m.Put(k, int(k))
+	}
+	// keys homed in bucket 0 spill into the overflow set
+	for i := 0; i < 12; i++ {
+		m.Put(intKey(i<<8), i<<8)
+	}
+	require.NotEmpty(t, m.overflow)
+
+	m.Range(func(k intKey, v int) bool {
+		slot, ok := m.SlotOf(k)
+		if m.findOverflow(k) >= 0 {
+			require.False(t, ok)
+			return true
+		}
+
+		require.True(t, ok)
+		require.Equal(t, m.lookup(m.hashKey(k), k), &m.entries[slot])
+
+		got, _ := m.ValueAt(uint32(slot))
+		require.Equal(t, v, got)
+		return true
+	})
+
+	_, ok := m.SlotOf(1 << 12)
+	require.False(t, ok)
+}
+
+func TestTombstones(t *testing.T) {
+	m := New[intKey, int](Config{Size: 1 << 8, BucketSize: 8, Stats: true, UseTombstones: true})
+
+	// fill bucket 0, and make keys of the following buckets spill past it
+	for i := 0; i < 8; i++ {
+		require.True(t, m.Put(intKey(i*(1<<8)), i))
+	}
+	for i := 1; i < 8; i++ {
+		require.True(t, m.Put(intKey(i), i))
+	}
+
+	k := intKey(3 * (1 << 8))
+	slot := m.findEntry(m.hashKey(k), k)
+	neighbors := append([]uint32(nil), m.neighbors...)
+
+	_, ok := m.Delete(k)
+	require.True(t, ok)
+	require.Equal(t, 1, m.tombs)
+	require.Equal(t, neighbors, m.neighbors)
+	require.Equal(t, 14, m.Len())
+	require.NoError(t, m.Validate())
+
+	_, ok = m.Get(k)
+	require.False(t, ok)
+
+	reshifts := m.Stats().Reshifts
+	require.True(t, m.Put(intKey(100*(1<<8)), 100))
+	require.Equal(t, slot, m.findEntry(0, intKey(100*(1<<8))))
+	require.Equal(t, reshifts, m.Stats().Reshifts)
+	require.Zero(t, m.tombs)
+	require.NoError(t, m.Validate())
+
+	// tombstones are not visited
+	m.Delete(intKey(5))
+	count := 0
+	m.Range(func(intKey, int) bool {
+		count++
+		return true
+	})
+	require.Equal(t, m.Len(), count)
+	require.Len(t, m.OccupiedSlots(), m.Len())
+	require.NoError(t, m.Clone().Validate())
+}
+
+func TestTombstoneMidChain(t *testing.T) {
+	m := New[intKey, int](Config{Size: 64, BucketSize: 8, UseTombstones: true})
+
+	// keys homed in bucket 3 form a chain over slots 3 to 7
+	chain := []intKey{3, 67, 131, 195, 259}
+	for i, k := range chain {
+		require.True(t, m.Put(k, i))
+	}
+
+	mid := m.findEntry(3, 131)
+	_, ok := m.Delete(131)
+	require.True(t, ok)
+	require.Same(t, m.tomb, m.entries[mid])
+	require.NotZero(t, m.neighbors[3]&(1<<(31-(mid-3))))
+
+	// the tombstone is skipped, not taken for the end of the chain
+	_, ok = m.Get(131)
+	require.False(t, ok)
+	for i, k := range chain {
+		if k == 131 {
+			continue
+		}
+		v, ok := m.Get(k)
+		require.True(t, ok)
+		require.Equal(t, i, v)
+
+		v, found, exhausted := m.GetBudgeted(k, -1)
+		require.True(t, found)
+		require.False(t, exhausted)
+		require.Equal(t, i, v)
+	}
+
+	h := m.Lookup(259)
+	v, ok := h.Value()
+	require.True(t, ok)
+	require.Equal(t, 4, v)
+	require.NoError(t, m.Validate())
+}
+
+func TestTombstonesPurge(t *testing.T) {
+	m := New[intKey, int](Config{Size: 1 << 8, BucketSize: 32, UseTombstones: true})
+	for i := 0; i < 200; i++ {
+		require.True(t, m.Put(intKey(i), i))
+	}
+
+	// tombstones are purged once they outnumber the empty slots
+	for i := 0; i < 100; i++ {
+		m.Delete(intKey(i))
+		require.LessOrEqual(t, m.tombs, m.size-m.n-m.tombs+1)
+		require.NoError(t, m.Validate())
+	}
+	require.Less(t, m.tombs, 100)
+
+	r := rand.New(rand.NewSource(1))
+	keys := make(map[intKey]bool)
+	for i := 100; i < 200; i++ {
+		keys[intKey(i)] = true
+	}
+	for i := 0; i < 10000; i++ {
+		k := intKey(r.Intn(1 << 10))
+		if keys[k] {
+			m.Delete(k)
+			delete(keys, k)
+		} else if m.Put(k, int(k)) {
+			keys[k] = true
+		}
+	}
+	require.NoError(t, m.Validate())
+	require.Equal(t, len(keys), m.Len())
+	for k := range keys {
+		v, ok := m.Get(k)
+		require.True(t, ok)
+		require.Equal(t, int(k), v)
+	}
+
+	require.NoError(t, m.Resize(1<<9))
+	require.Zero(t, m.tombs)
+	require.NoError(t, m.Validate())
+}
+
+func TestRangeReverse(t *testing.T) {
+	m := New[intKey, int](Config{Size: 1 << 8, BucketSize: 8, UseTombstones: true})
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 150; i++ {
+		k := intKey(r.Intn(1 << 10))
+		m.Put(k, int(k))
+	}
+	for k := intKey(0); k < 1<<10; k += 7 {
+		m.Delete(k)
+	}
+
+	var forward, reverse []intKey
+	m.Range(func(k intKey, _ int) bool {
+		forward = append(forward, k)
+		return true
+	})
+	m.RangeReverse(func(k intKey, v int) bool {
+		require.Equal(t, int(k), v)
+		reverse = append(reverse, k)
+		return true
+	})
+	require.Len(t, reverse, m.Len())
+	slices.Reverse(reverse)
+	require.Equal(t, forward, reverse)
+
+	// slots are visited by descending index
+	last := -1
+	m.RangeReverse(func(k intKey, _ int) bool {
+		j := m.findEntry(m.hashKey(k), k)
+		if last >= 0 {
+			require.Less(t, j, last)
+		}
+		last = j
+		return true
+	})
+
+	count := 0
+	m.RangeReverse(func(intKey, int) bool {
+		count++
+		return count < 3
+	})
+	require.Equal(t, 3, count)
+}
+
+func TestProbeStepIndependence(t *testing.T) {
+	// home buckets are the low bits of the murmur mix: if it also picked among the 4 steps,
+	// keys sharing a home bucket would all get the same one
+	const bucketSize = 5
+	m := New[intKey, int](Config{Size: 1 << 10, BucketSize: bucketSize, HashFinalizer: MurmurFinalizer})
+
+	steps := make(map[int]bool)
+	for k := intKey(0); len(steps) < bucketSize-1 && k < 1<<16; k++ {
+		if m.hashKey(k) == 0 {
+			steps[probeStep(uint32(k), bucketSize)] = true
+		}
+	}
+	require.Len(t, steps, bucketSize-1)
+}
@@ -1,7 +1,9 @@
 package hopmap_test
 
 import (
+	"errors"
 	"math/rand"
+	"strconv"
 	"testing"
 	"time"
 
@@ -20,16 +22,21 @@ func (x Key) HashCode() uint32 {
 }
 
 func TestPutAndGet(t *testing.T) {
-	rand.Seed(time.Now().UnixNano())
+	// the seed drives both the key sequence and the placement of keys, so logging it
+	// is enough to reproduce a failure
+	seed := time.Now().UnixNano()
+	t.Logf("seed: %d", seed)
+	r := rand.New(rand.NewSource(seed))
 
 	m := hopmap.New[Key, uint32](hopmap.Config{
 		Size:       1 << 12,
 		BucketSize: 32,
+		Seed:       uint32(seed),
 	})
 
 	keys := make([]Key, 0)
 	for ok := true; ok; {
-		k := rand.Int31()
+		k := r.Int31()
 		ok = m.Put(Key(k), uint32(k+1))
 
 		if !ok {
@@ -44,3 +51,1277 @@ func TestPutAndGet(t *testing.T) {
 		require.Equal(t, uint32(v), uint32(k+1))
 	}
 }
+
+func TestCloneCompact(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.Config{
+		Size:       1 << 16,
+		BucketSize: 32,
+	})
+
+	for i := 0; i < 1<<15; i++ {
+		require.True(t, m.Put(Key(i), uint32(i)))
+	}
+	for i := 100; i < 1<<15; i++ {
+		_, ok := m.Delete(Key(i))
+		require.True(t, ok)
+	}
+
+	require.True(t, m.Pin(7))
+
+	c, err := m.CloneCompact()
+	require.NoError(t, err)
+	require.Equal(t, 256, c.Size())
+	require.True(t, c.IsPinned(7))
+	require.Equal(t, m.Len(), c.Len())
+	require.Equal(t, 1<<16, m.Clone().Size())
+
+	for i := 0; i < 100; i++ {
+		v, ok := c.Get(Key(i))
+		require.True(t, ok)
+		require.Equal(t, uint32(i), v)
+	}
+}
+
+func TestCloneCompactOverflow(t *testing.T) {
+	m := hopmap.New[constKey, int](hopmap.Config{Size: 64, BucketSize: 4, AllowOverflow: true})
+	for i := 0; i < 8; i++ {
+		require.True(t, m.Put(constKey(i), i))
+	}
+
+	c, err := m.CloneCompact()
+	require.NoError(t, err)
+	require.Equal(t, 16, c.Size())
+	require.Equal(t, 8, c.Len())
+	for i := 0; i < 8; i++ {
+		v, ok := c.Get(constKey(i))
+		require.True(t, ok)
+		require.Equal(t, i, v)
+	}
+}
+
+func TestGetWithHook(t *testing.T) {
+	type item struct {
+		value, hits int
+	}
+
+	m := hopmap.New[Key, item](hopmap.DefaultConfig())
+	m.Put(1, item{value: 10})
+
+	calls := 0
+	onHit := func(it *item) {
+		calls++
+		it.hits++
+	}
+
+	v, ok := m.GetWithHook(1, onHit)
+	require.True(t, ok)
+	require.Equal(t, 1, calls)
+	require.Equal(t, item{value: 10, hits: 1}, v)
+
+	_, ok = m.GetWithHook(2, onHit)
+	require.False(t, ok)
+	require.Equal(t, 1, calls)
+
+	v, _ = m.Get(1)
+	require.Equal(t, 1, v.hits)
+}
+
+func BenchmarkGet(b *testing.B) {
+	m := hopmap.New[Key, uint32](hopmap.Config{
+		Size:       1 << 20,
+		BucketSize: 32,
+	})
+
+	r := rand.New(rand.NewSource(1))
+	keys := make([]Key, 1<<19)
+	for i := range keys {
+		keys[i] = Key(r.Uint32())
+		m.Put(keys[i], uint32(i))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Get(keys[i&(len(keys)-1)])
+	}
+}
+
+// BenchmarkPut measures inserts of new keys, with a hash function costly enough to matter.
+func BenchmarkPut(b *testing.B) {
+	m := hopmap.New[strKey, uint32](hopmap.Config{
+		Size:       1 << 12,
+		BucketSize: 32,
+	})
+
+	r := rand.New(rand.NewSource(1))
+	keys := make([]strKey, 1<<11)
+	for i := range keys {
+		s := strconv.FormatUint(r.Uint64(), 36) + strconv.FormatUint(r.Uint64(), 36)
+		keys[i] = strKey{&s}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if i&(len(keys)-1) == 0 {
+			m.Clear()
+		}
+		m.Put(keys[i&(len(keys)-1)], uint32(i))
+	}
+}
+
+func TestPutNewKeys(t *testing.T) {
+	configs := map[string]hopmap.Config{
+		"resize":     {Size: 16, BucketSize: 8, AutoResize: true, MaxLoad: 0.9, MinLoad: 0.2},
+		"tombstones": {Size: 1 << 10, BucketSize: 8, UseTombstones: true, AllowOverflow: true},
+		"overflow":   {Size: 1 << 8, BucketSize: 4, AllowOverflow: true, HashFinalizer: hopmap.MurmurFinalizer},
+	}
+
+	for name, c := range configs {
+		t.Run(name, func(t *testing.T) {
+			m := hopmap.New[Key, int](c)
+			want := make(map[Key]int)
+			r := rand.New(rand.NewSource(1))
+
+			for i := 0; i < 5000; i++ {
+				k := Key(r.Intn(1 << 10))
+				switch r.Intn(5) {
+				case 0:
+					m.Delete(k)
+					delete(want, k)
+				case 1:
+					if m.PutUnique(k, i) == nil {
+						want[k] = i
+					}
+				case 2:
+					// the factory grows the map before the key is inserted
+					other := Key(1<<10 + i)
+					_, err := m.GetOrCompute(k, func(Key) (int, error) {
+						require.True(t, m.Put(other, i))
+						want[other] = i
+						return i, nil
+					})
+					require.NoError(t, err)
+					if _, ok := want[k]; !ok {
+						want[k] = i
+					}
+				default:
+					require.True(t, m.Put(k, i))
+					want[k] = i
+				}
+			}
+
+			require.NoError(t, m.Validate())
+			require.Equal(t, len(want), m.Len())
+			for k, v := range want {
+				got, ok := m.Get(k)
+				require.True(t, ok)
+				require.Equal(t, v, got)
+			}
+		})
+	}
+}
+
+func TestPutHashesOnce(t *testing.T) {
+	calls := 0
+	m := hopmap.New[countedKey, int](hopmap.Config{Size: 64, BucketSize: 8})
+	for i := uint32(0); i < 32; i++ {
+		require.True(t, m.Put(countedKey{i * 7, &calls}, int(i)))
+	}
+	require.Equal(t, 32, calls)
+
+	// growing rehashes every entry, and the new key once more
+	calls = 0
+	g := hopmap.New[countedKey, int](hopmap.Config{Size: 2, BucketSize: 2, AutoResize: true, MaxLoad: 1})
+	g.Put(countedKey{0, &calls}, 0)
+	g.Put(countedKey{1, &calls}, 1)
+	calls = 0
+	require.True(t, g.Put(countedKey{2, &calls}, 2))
+	require.Equal(t, 4, g.Size())
+	require.Equal(t, 1+2+1, calls)
+}
+
+func TestGetBudgeted(t *testing.T) {
+	m := hopmap.New[constKey, int](hopmap.Config{Size: 64, BucketSize: 32, AllowOverflow: true, Stats: true})
+
+	// all the keys share bucket 0, and the last ones overflow
+	for i := 0; i < 40; i++ {
+		require.True(t, m.Put(constKey(i), i))
+	}
+
+	v, found, exhausted := m.GetBudgeted(0, 1)
+	require.True(t, found)
+	require.False(t, exhausted)
+	require.Zero(t, v)
+
+	_, found, exhausted = m.GetBudgeted(19, 19)
+	require.False(t, found)
+	require.True(t, exhausted)
+
+	v, found, exhausted = m.GetBudgeted(19, 20)
+	require.True(t, found)
+	require.False(t, exhausted)
+	require.Equal(t, 19, v)
+
+	// the overflow set draws from the same budget
+	_, _, exhausted = m.GetBudgeted(35, 32)
+	require.True(t, exhausted)
+	v, found, _ = m.GetBudgeted(35, -1)
+	require.True(t, found)
+	require.Equal(t, 35, v)
+
+	// a miss is only certain once every candidate has been compared
+	_, found, exhausted = m.GetBudgeted(100, 39)
+	require.False(t, found)
+	require.True(t, exhausted)
+	_, found, exhausted = m.GetBudgeted(100, 40)
+	require.False(t, found)
+	require.False(t, exhausted)
+
+	require.Equal(t, uint64(7), m.Stats().Gets)
+}
+
+func TestGetBatchInto(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: 64, BucketSize: 8})
+	for i := 0; i < 10; i++ {
+		m.Put(Key(i), uint32(i*10))
+	}
+
+	keys := []Key{3, 42, 0, 9, 10}
+	values, found := make([]uint32, len(keys)), make([]bool, len(keys))
+	m.GetBatchInto(keys, values, found)
+	require.Equal(t, []uint32{30, 0, 0, 90, 0}, values)
+	require.Equal(t, []bool{true, false, true, true, false}, found)
+
+	values, found = m.GetMany(keys)
+	require.Equal(t, []uint32{30, 0, 0, 90, 0}, values)
+	require.Equal(t, []bool{true, false, true, true, false}, found)
+
+	require.Panics(t, func() {
+		m.GetBatchInto(keys, values[:2], found)
+	})
+}
+
+func BenchmarkGetBatch(b *testing.B) {
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: 1 << 16, BucketSize: 32})
+
+	r := rand.New(rand.NewSource(1))
+	keys := make([]Key, 1<<10)
+	for i := range keys {
+		keys[i] = Key(r.Uint32())
+		if i%2 == 0 {
+			m.Put(keys[i], uint32(i))
+		}
+	}
+
+	b.Run("GetMany", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			m.GetMany(keys)
+		}
+	})
+
+	b.Run("GetBatchInto", func(b *testing.B) {
+		values, found := make([]uint32, len(keys)), make([]bool, len(keys))
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			m.GetBatchInto(keys, values, found)
+		}
+	})
+}
+
+func TestShrink(t *testing.T) {
+	resizes := func(eager bool) uint64 {
+		m := hopmap.New[Key, uint32](hopmap.Config{
+			Size:        1 << 10,
+			BucketSize:  32,
+			AutoResize:  true,
+			MaxLoad:     0.75,
+			MinLoad:     0.4,
+			EagerResize: eager,
+			Stats:       true,
+		})
+
+		// crossing MaxLoad lands the map right below MinLoad
+		for i := 0; i <= 768; i++ {
+			require.True(t, m.Put(Key(i), uint32(i)))
+		}
+		require.Equal(t, 1<<11, m.Size())
+
+		for i := 0; i < 100; i++ {
+			_, ok := m.Delete(768)
+			require.True(t, ok)
+			require.True(t, m.Put(768, 768))
+		}
+		require.NoError(t, m.Validate())
+
+		// draining the map shrinks it either way
+		for i := 0; i <= 768; i++ {
+			_, ok := m.Delete(Key(i))
+			require.True(t, ok)
+		}
+		require.Less(t, m.Size(), 1<<4)
+		return m.Stats().Resizes
+	}
+
+	damped, eager := resizes(false), resizes(true)
+	require.Less(t, damped, uint64(20))
+	require.Greater(t, eager, uint64(200))
+}
+
+func TestPrewarm(t *testing.T) {
+	for _, size := range []int{1, 2, 1 << 4, 1<<10 + 1} {
+		m := hopmap.New[Key, uint32](hopmap.Config{Size: size, BucketSize: 32})
+		m.Prewarm()
+
+		for i := 0; i < size/2; i++ {
+			m.Put(Key(i), uint32(i))
+		}
+		m.Prewarm()
+		require.Equal(t, size/2, m.Len())
+		require.NoError(t, m.Validate())
+	}
+}
+
+// BenchmarkPrewarm measures the latency of a burst of lookups right after the caches
+// have been flushed, with and without prewarming the table.
+func BenchmarkPrewarm(b *testing.B) {
+	const size = 1 << 20
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: size, BucketSize: 32})
+
+	r := rand.New(rand.NewSource(1))
+	keys := make([]Key, size/2)
+	for i := range keys {
+		keys[i] = Key(r.Uint32())
+		m.Put(keys[i], uint32(i))
+	}
+
+	flush := make([]byte, 64<<20)
+	for _, prewarm := range []bool{false, true} {
+		name := "Cold"
+		if prewarm {
+			name = "Prewarmed"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				for j := range flush {
+					flush[j]++
+				}
+				if prewarm {
+					m.Prewarm()
+				}
+				b.StartTimer()
+
+				for _, k := range keys[:1<<10] {
+					m.Get(k)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRangeSparse(b *testing.B) {
+	const size = 1 << 20
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: size, BucketSize: 32})
+
+	r := rand.New(rand.NewSource(1))
+	for m.Len() < size/100 {
+		k := r.Uint32()
+		m.Put(Key(k), k)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var sum uint32
+		m.Range(func(k Key, v uint32) bool {
+			sum += v
+			return true
+		})
+	}
+}
+
+func TestContainsAllAny(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: 64, BucketSize: 8})
+	for i := 0; i < 10; i++ {
+		m.Put(Key(i), uint32(i))
+	}
+
+	require.True(t, m.ContainsAll([]Key{0, 5, 9}))
+	require.False(t, m.ContainsAll([]Key{0, 5, 10}))
+	require.True(t, m.ContainsAll(nil))
+
+	require.True(t, m.ContainsAny([]Key{42, 5}))
+	require.False(t, m.ContainsAny([]Key{10, 11, 42}))
+	require.False(t, m.ContainsAny(nil))
+}
+
+func TestTransformValues(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: 64, BucketSize: 8, CopyOnOverwrite: true})
+	for i := 0; i < 40; i++ {
+		m.Put(Key(i), uint32(i))
+	}
+	p, _ := m.GetPointer(7)
+
+	m.TransformValues(func(k Key, v uint32) uint32 { return 2 * v })
+	require.Equal(t, 40, m.Len())
+	for i := 0; i < 40; i++ {
+		v, ok := m.Get(Key(i))
+		require.True(t, ok)
+		require.Equal(t, uint32(2*i), v)
+	}
+	require.Equal(t, uint32(7), *p)
+}
+
+func TestUpdateWhere(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: 64, BucketSize: 4, AllowOverflow: true})
+	for i := 0; i < 40; i++ {
+		m.Put(Key(i), uint32(i))
+	}
+	// keys homed in bucket 0 spill into the overflow set
+	for i := 1; i < 8; i++ {
+		m.Put(Key(i*64), uint32(i*64))
+	}
+
+	even := func(k Key, _ uint32) bool { return k%2 == 0 }
+	n := m.UpdateWhere(even, func(v uint32) uint32 { return v + 1 })
+	require.Equal(t, 27, n)
+	require.Equal(t, 47, m.Len())
+
+	m.Range(func(k Key, v uint32) bool {
+		if k%2 == 0 {
+			require.Equal(t, uint32(k)+1, v)
+		} else {
+			require.Equal(t, uint32(k), v)
+		}
+		return true
+	})
+
+	require.Zero(t, m.UpdateWhere(func(Key, uint32) bool { return false }, nil))
+}
+
+func TestIngest(t *testing.T) {
+	for _, overwrite := range []bool{false, true} {
+		m := hopmap.New[Key, uint32](hopmap.Config{Size: 16, BucketSize: 8, AutoResize: true, MaxLoad: 0.75})
+		m.Put(0, 42)
+
+		ch := make(chan hopmap.Pair[Key, uint32], 16)
+		go func() {
+			for i := 0; i < 100; i++ {
+				ch <- hopmap.Pair[Key, uint32]{Key: Key(i), Value: uint32(i)}
+			}
+			close(ch)
+		}()
+
+		require.Equal(t, 99, m.Ingest(ch, overwrite))
+		require.Equal(t, 100, m.Len())
+		for i := 1; i < 100; i++ {
+			v, ok := m.Get(Key(i))
+			require.True(t, ok)
+			require.Equal(t, uint32(i), v)
+		}
+
+		v, _ := m.Get(0)
+		if overwrite {
+			require.Equal(t, uint32(0), v)
+		} else {
+			require.Equal(t, uint32(42), v)
+		}
+	}
+}
+
+func TestContainsValue(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: 16, BucketSize: 8})
+	for i := 0; i < 8; i++ {
+		m.Put(Key(i), uint32(i*10))
+	}
+
+	calls := 0
+	eq := func(a, b uint32) bool {
+		calls++
+		return a == b
+	}
+
+	require.True(t, m.ContainsValue(0, eq))
+	require.Equal(t, 1, calls)
+
+	calls = 0
+	require.False(t, m.ContainsValue(5, eq))
+	require.Equal(t, 8, calls)
+}
+
+func TestKeyOf(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: 16, BucketSize: 8})
+	for i := 0; i < 8; i++ {
+		m.Put(Key(i), uint32(i*10))
+	}
+
+	eq := func(a, b uint32) bool { return a == b }
+
+	k, ok := m.KeyOf(30, eq)
+	require.True(t, ok)
+	require.Equal(t, Key(3), k)
+
+	_, ok = m.KeyOf(35, eq)
+	require.False(t, ok)
+}
+
+func TestAccumulate(t *testing.T) {
+	sum := hopmap.New[Key, int](hopmap.Config{Size: 16, BucketSize: 8})
+	for i := 0; i < 10; i++ {
+		require.True(t, sum.Accumulate(Key(i%2), i, func(cur, delta int) int { return cur + delta }))
+	}
+	even, _ := sum.Get(0)
+	odd, _ := sum.Get(1)
+	require.Equal(t, 20, even)
+	require.Equal(t, 25, odd)
+
+	groups := hopmap.New[Key, []int](hopmap.Config{Size: 16, BucketSize: 8})
+	for i := 0; i < 6; i++ {
+		groups.Accumulate(Key(i%3), []int{i}, func(cur, delta []int) []int { return append(cur, delta...) })
+	}
+	g, _ := groups.Get(2)
+	require.Equal(t, []int{2, 5}, g)
+	require.Equal(t, 3, groups.Len())
+}
+
+func BenchmarkGetRandomLarge(b *testing.B) {
+	m := hopmap.New[Key, uint32](hopmap.Config{
+		Size:       1 << 23,
+		BucketSize: 32,
+	})
+
+	r := rand.New(rand.NewSource(1))
+	keys := make([]Key, 1<<22)
+	for i := range keys {
+		keys[i] = Key(r.Uint32())
+		m.Put(keys[i], uint32(i))
+	}
+	r.Shuffle(len(keys), func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.Get(keys[i&(len(keys)-1)])
+	}
+}
+
+func TestRange(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: 32, BucketSize: 8})
+	for i := 0; i < 10; i++ {
+		m.Put(Key(i), uint32(i+100))
+	}
+
+	seen := make(map[Key]uint32)
+	m.Range(func(k Key, v uint32) bool {
+		seen[k] = v
+		return true
+	})
+	require.Len(t, seen, 10)
+	for k, v := range seen {
+		require.Equal(t, uint32(k+100), v)
+	}
+
+	keys := 0
+	m.RangeKeys(func(k Key) bool {
+		keys++
+		return true
+	})
+	require.Equal(t, 10, keys)
+
+	keys = 0
+	m.RangeKeys(func(k Key) bool {
+		keys++
+		return keys < 3
+	})
+	require.Equal(t, 3, keys)
+
+	sum := uint32(0)
+	m.RangeValues(func(v uint32) bool {
+		sum += v
+		return true
+	})
+	require.Equal(t, uint32(1045), sum)
+
+	values := 0
+	m.RangeValues(func(v uint32) bool {
+		values++
+		return false
+	})
+	require.Equal(t, 1, values)
+}
+
+func TestGetPointer(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.DefaultConfig())
+	m.Put(1, 1)
+
+	p, ok := m.GetPointer(1)
+	require.True(t, ok)
+
+	*p = 2
+	v, _ := m.Get(1)
+	require.Equal(t, uint32(2), v)
+
+	m.Put(1, 3)
+	require.Equal(t, uint32(3), *p)
+
+	_, ok = m.GetPointer(2)
+	require.False(t, ok)
+}
+
+func TestCopyOnOverwrite(t *testing.T) {
+	c := hopmap.DefaultConfig()
+	c.CopyOnOverwrite = true
+
+	m := hopmap.New[Key, uint32](c)
+	m.Put(1, 1)
+
+	p, _ := m.GetPointer(1)
+	m.Put(1, 2)
+	require.Equal(t, uint32(1), *p)
+
+	v, _ := m.Get(1)
+	require.Equal(t, uint32(2), v)
+}
+
+func TestDeleteStability(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: 16, BucketSize: 8})
+
+	a, b, c := Key(1), Key(5), Key(17) // a and c share bucket 1
+	m.Put(a, 1)
+	m.Put(b, 2)
+	m.Put(c, 3)
+
+	pb, _ := m.GetPointer(b)
+	pc, _ := m.GetPointer(c)
+
+	_, ok := m.Delete(a)
+	require.True(t, ok)
+
+	require.Equal(t, uint32(2), *pb)
+	require.Equal(t, uint32(3), *pc)
+
+	*pc = 4
+	v, ok := m.Get(c)
+	require.True(t, ok)
+	require.Equal(t, uint32(4), v)
+}
+
+func TestRangeMut(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: 32, BucketSize: 8})
+	for i := 0; i < 20; i++ {
+		m.Put(Key(i), uint32(i))
+	}
+
+	m.RangeMut(func(k Key, v *uint32) hopmap.Action {
+		*v *= 2
+		return hopmap.Keep
+	})
+	for i := 0; i < 20; i++ {
+		v, _ := m.Get(Key(i))
+		require.Equal(t, uint32(2*i), v)
+	}
+
+	m.RangeMut(func(k Key, v *uint32) hopmap.Action {
+		if k%2 == 0 {
+			return hopmap.Delete
+		}
+		return hopmap.Keep
+	})
+	require.Equal(t, 10, m.Len())
+	for i := 0; i < 20; i++ {
+		_, ok := m.Get(Key(i))
+		require.Equal(t, i%2 == 1, ok)
+	}
+
+	visited := 0
+	m.RangeMut(func(k Key, v *uint32) hopmap.Action {
+		visited++
+		return hopmap.Stop
+	})
+	require.Equal(t, 1, visited)
+	require.Equal(t, 10, m.Len())
+}
+
+func TestPartition(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: 64, BucketSize: 8})
+	for i := 0; i < 40; i++ {
+		m.Put(Key(i), uint32(i))
+	}
+
+	even, odd := m.Partition(func(k Key, v uint32) bool { return k%2 == 0 })
+	require.Equal(t, 40, m.Len())
+	require.Equal(t, 20, even.Len())
+	require.Equal(t, 20, odd.Len())
+
+	m.Range(func(k Key, v uint32) bool {
+		ve, inEven := even.Get(k)
+		vo, inOdd := odd.Get(k)
+		require.NotEqual(t, inEven, inOdd)
+		if inEven {
+			require.Equal(t, v, ve)
+		} else {
+			require.Equal(t, v, vo)
+		}
+		return true
+	})
+}
+
+type strKey struct {
+	s *string
+}
+
+func (x strKey) Equals(y strKey) bool {
+	return *x.s == *y.s
+}
+
+func (x strKey) HashCode() uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(*x.s); i++ {
+		h = (h ^ uint32((*x.s)[i])) * 16777619
+	}
+	return h
+}
+
+func TestIntern(t *testing.T) {
+	m := hopmap.New[strKey, struct{}](hopmap.DefaultConfig())
+
+	a, b := "hopmap", "hop"
+	b += "map"
+
+	first := m.Intern(strKey{&a})
+	second := m.Intern(strKey{&b})
+
+	require.Same(t, &a, first.s)
+	require.Same(t, &a, second.s)
+	require.Equal(t, 1, m.Len())
+}
+
+func TestRangeOrdered(t *testing.T) {
+	build := func() *hopmap.Map[Key, uint32] {
+		m := hopmap.New[Key, uint32](hopmap.Config{Size: 64, BucketSize: 8})
+
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < 40; i++ {
+			k := r.Uint32()
+			m.Put(Key(k), k)
+		}
+		return m
+	}
+
+	collect := func(m *hopmap.Map[Key, uint32]) []Key {
+		var keys []Key
+		m.RangeOrdered(func(k Key, _ uint32) bool {
+			keys = append(keys, k)
+			return true
+		})
+		return keys
+	}
+
+	a, b := collect(build()), collect(build())
+	require.Len(t, a, 40)
+	require.Equal(t, a, b)
+
+	for i := 1; i < len(a); i++ {
+		require.LessOrEqual(t, a[i-1]%64, a[i]%64)
+	}
+}
+
+func TestRoundSize(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: 1000, BucketSize: 16})
+	require.Equal(t, 1024, m.Size())
+
+	require.NoError(t, m.Resize(3000))
+	require.Equal(t, 4096, m.Size())
+
+	require.NoError(t, m.Resize(4096))
+	require.Equal(t, 4096, m.Size())
+}
+
+func TestRangeBuckets(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: 64, BucketSize: 4, AllowOverflow: true})
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 30; i++ {
+		k := r.Uint32()
+		m.Put(Key(k), k)
+	}
+	// keys homed in bucket 40 spill into the overflow set
+	for i := uint32(0); i < 8; i++ {
+		m.Put(Key(40+i*64), i)
+	}
+
+	visited := make(map[Key]int)
+	collect := func(start, end uint32) {
+		m.RangeBuckets(start, end, func(k Key, _ uint32) bool {
+			require.GreaterOrEqual(t, uint32(k)%64, start)
+			require.Less(t, uint32(k)%64, end)
+			visited[k]++
+			return true
+		})
+	}
+	collect(0, 40)
+	collect(40, 64)
+
+	all := make(map[Key]int)
+	m.Range(func(k Key, _ uint32) bool {
+		all[k]++
+		return true
+	})
+	require.Equal(t, all, visited)
+	require.Len(t, visited, m.Len())
+
+	count := 0
+	m.RangeBuckets(0, 64, func(Key, uint32) bool {
+		count++
+		return count < 3
+	})
+	require.Equal(t, 3, count)
+
+	require.Panics(t, func() { m.RangeBuckets(10, 5, nil) })
+	require.Panics(t, func() { m.RangeBuckets(0, 65, nil) })
+}
+
+func TestConfig(t *testing.T) {
+	template := hopmap.Config{Size: 1000, BucketSize: 16, Seed: 7}
+
+	c := template.Clone()
+	c.Size = 100
+	require.Equal(t, 1000, template.Size)
+
+	m := hopmap.New[Key, uint32](template)
+	require.Equal(t, 1024, m.Size())
+	require.Equal(t, hopmap.Config{Size: 1024, BucketSize: 16, Seed: 7}, m.Config())
+
+	require.NoError(t, m.Resize(3000))
+	require.Equal(t, 4096, m.Config().Size)
+}
+
+// skewedPairs returns n distinct keys whose home buckets are clustered at
+// multiples of 4, mimicking a skewed key distribution.
+func skewedPairs(n, size int) []hopmap.Pair[Key, uint32] {
+	r := rand.New(rand.NewSource(1))
+
+	seen := make(map[Key]bool)
+	pairs := make([]hopmap.Pair[Key, uint32], 0, n)
+	for len(pairs) < n {
+		home := r.Intn(size)
+		if r.Intn(5) > 0 {
+			home -= home % 4
+		}
+
+		k := Key(home + size*r.Intn(1<<10))
+		if !seen[k] {
+			seen[k] = true
+			pairs = append(pairs, hopmap.Pair[Key, uint32]{Key: k, Value: uint32(len(pairs))})
+		}
+	}
+	return pairs
+}
+
+func TestDoubleHashing(t *testing.T) {
+	const size = 1 << 12
+	pairs := skewedPairs(size*3/4, size)
+
+	reshifts := make(map[bool]uint64)
+	for _, doubleHashing := range []bool{false, true} {
+		m := hopmap.New[Key, uint32](hopmap.Config{
+			Size:          size,
+			BucketSize:    32,
+			Stats:         true,
+			DoubleHashing: doubleHashing,
+		})
+
+		for _, p := range pairs {
+			require.True(t, m.Put(p.Key, p.Value))
+		}
+		require.NoError(t, m.Validate())
+
+		for _, p := range pairs {
+			v, ok := m.Get(p.Key)
+			require.True(t, ok)
+			require.Equal(t, p.Value, v)
+		}
+		reshifts[doubleHashing] = m.Stats().Reshifts
+	}
+	require.Less(t, reshifts[true], reshifts[false])
+}
+
+// churn fills a map of the given size up to load, then replaces random keys n times.
+// It returns the keys held by the map.
+func churn(m *hopmap.Map[Key, uint32], size int, load float64, n int) []Key {
+	r := rand.New(rand.NewSource(1))
+	keys := make([]Key, 0, int(load*float64(size)))
+	for len(keys) < cap(keys) {
+		k := Key(r.Uint32())
+		if m.Put(k, uint32(k)) {
+			keys = append(keys, k)
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		x := r.Intn(len(keys))
+		m.Delete(keys[x])
+
+		k := Key(r.Uint32())
+		if m.Put(k, uint32(k)) {
+			keys[x] = k
+		} else {
+			keys[x] = keys[len(keys)-1]
+			keys = keys[:len(keys)-1]
+		}
+	}
+	return keys
+}
+
+func TestProbeStrategy(t *testing.T) {
+	const size = 1 << 12
+
+	failures := make(map[hopmap.ProbeStrategy]uint64)
+	for _, strategy := range []hopmap.ProbeStrategy{hopmap.Forward, hopmap.Bidirectional} {
+		m := hopmap.New[Key, uint32](hopmap.Config{
+			Size:          size,
+			BucketSize:    32,
+			Stats:         true,
+			ProbeStrategy: strategy,
+		})
+
+		keys := churn(m, size, 0.9, 4*size)
+		require.NoError(t, m.Validate())
+		require.Equal(t, len(keys), m.Len())
+
+		for _, k := range keys {
+			v, ok := m.Get(k)
+			require.True(t, ok)
+			require.Equal(t, uint32(k), v)
+		}
+		failures[strategy] = m.Stats().Failures
+	}
+	require.Less(t, failures[hopmap.Bidirectional], failures[hopmap.Forward])
+}
+
+func BenchmarkProbeStrategy(b *testing.B) {
+	const size = 1 << 14
+
+	for _, strategy := range []hopmap.ProbeStrategy{hopmap.Forward, hopmap.Bidirectional} {
+		name := "Forward"
+		if strategy == hopmap.Bidirectional {
+			name = "Bidirectional"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			var stats hopmap.Stats
+			for i := 0; i < b.N; i++ {
+				m := hopmap.New[Key, uint32](hopmap.Config{
+					Size:          size,
+					BucketSize:    32,
+					Stats:         true,
+					ProbeStrategy: strategy,
+				})
+				churn(m, size, 0.9, size)
+
+				s := m.Stats()
+				stats.Reshifts += s.Reshifts
+				stats.Failures += s.Failures
+			}
+			b.ReportMetric(float64(stats.Reshifts)/float64(b.N), "reshifts/op")
+			b.ReportMetric(float64(stats.Failures)/float64(b.N), "failures/op")
+		})
+	}
+}
+
+func TestMaxProbeBuckets(t *testing.T) {
+	build := func(c hopmap.Config) *hopmap.Map[Key, uint32] {
+		m := hopmap.New[Key, uint32](c)
+		for i := 0; i < 16; i++ {
+			require.True(t, m.Put(Key(i), uint32(i)))
+		}
+		return m
+	}
+
+	// the first empty slot lies 16 slots away from bucket 0
+	c := hopmap.Config{Size: 256, BucketSize: 4}
+	require.True(t, build(c).Put(256, 0))
+
+	c.MaxProbeBuckets = 2
+	m := build(c)
+	require.ErrorIs(t, m.TryPut(256, 0), hopmap.ErrTableFull)
+	require.True(t, m.Put(12+256, 0))
+	require.NoError(t, m.Validate())
+
+	c.ProbeStrategy = hopmap.Bidirectional
+	require.False(t, build(c).Put(256, 0))
+
+	// a bounded search grows an auto-resizing map instead
+	c.AutoResize = true
+	m = build(c)
+	require.True(t, m.Put(256, 0))
+	require.Equal(t, 512, m.Size())
+}
+
+func TestMaxReshift(t *testing.T) {
+	build := func(c hopmap.Config) *hopmap.Map[Key, uint32] {
+		m := hopmap.New[Key, uint32](c)
+		for i := 0; i < 64; i++ {
+			require.True(t, m.Put(Key(i), uint32(i)))
+		}
+		return m
+	}
+
+	// making room for a key homed in bucket 0 takes a cascade of reshifts through the cluster
+	c := hopmap.Config{Size: 256, BucketSize: 4, Stats: true}
+	m := build(c)
+	require.True(t, m.Put(256, 0))
+	require.Greater(t, m.Stats().Reshifts, uint64(8))
+
+	c.MaxReshift = 8
+	m = build(c)
+	require.ErrorIs(t, m.TryPut(256, 0), hopmap.ErrTableFull)
+	require.Equal(t, uint64(8), m.Stats().Reshifts)
+	require.Equal(t, 64, m.Len())
+	require.NoError(t, m.Validate())
+
+	c.AutoResize = true
+	m = build(c)
+	require.True(t, m.Put(256, 0))
+	require.Equal(t, 512, m.Size())
+	require.NoError(t, m.Validate())
+}
+
+// BenchmarkFailedPut measures insertions failing on a full table,
+// where an unbounded search for an empty slot scans the whole table.
+func BenchmarkFailedPut(b *testing.B) {
+	const size = 1 << 16
+
+	for name, limit := range map[string]int{"Unbounded": 0, "Bounded": 4} {
+		b.Run(name, func(b *testing.B) {
+			m := hopmap.New[Key, uint32](hopmap.Config{Size: size, BucketSize: 32, MaxProbeBuckets: limit})
+			for i := 0; i < size; i++ {
+				m.Put(Key(i), uint32(i))
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if m.Put(Key(size+i%size), 0) {
+					b.Fatal("insertion into a full table succeeded")
+				}
+			}
+		})
+	}
+}
+
+func TestGetOrCompute(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.DefaultConfig())
+	m.Put(1, 10)
+
+	calls := 0
+	factory := func(k Key) (uint32, error) {
+		calls++
+		return uint32(k) * 10, nil
+	}
+
+	v, err := m.GetOrCompute(1, factory)
+	require.NoError(t, err)
+	require.Equal(t, uint32(10), v)
+	require.Zero(t, calls)
+
+	v, err = m.GetOrCompute(2, factory)
+	require.NoError(t, err)
+	require.Equal(t, uint32(20), v)
+	require.Equal(t, 1, calls)
+
+	v, _ = m.Get(2)
+	require.Equal(t, uint32(20), v)
+
+	errFactory := errors.New("factory failed")
+	_, err = m.GetOrCompute(3, func(Key) (uint32, error) { return 0, errFactory })
+	require.ErrorIs(t, err, errFactory)
+
+	require.Panics(t, func() {
+		m.GetOrCompute(4, func(Key) (uint32, error) { panic("boom") })
+	})
+
+	_, ok := m.Get(3)
+	require.False(t, ok)
+	_, ok = m.Get(4)
+	require.False(t, ok)
+	require.Equal(t, 2, m.Len())
+	require.NoError(t, m.Validate())
+}
+
+func TestSetBucketSize(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: 64, BucketSize: 4, AllowOverflow: true})
+	strict := hopmap.New[Key, uint32](hopmap.Config{Size: 64, BucketSize: 4})
+
+	// eight keys share bucket 0, but only four fit into its neighborhood
+	for i := 0; i < 8; i++ {
+		require.True(t, m.Put(Key(i*64), uint32(i)))
+		require.Equal(t, i < 4, strict.Put(Key(i*64), uint32(i)))
+	}
+
+	require.NoError(t, m.SetBucketSize(8))
+	require.Equal(t, 8, m.Config().BucketSize)
+	require.Len(t, m.OccupiedSlots(), 8)
+	require.NoError(t, m.Validate())
+	for i := 0; i < 8; i++ {
+		v, ok := m.Get(Key(i * 64))
+		require.True(t, ok)
+		require.Equal(t, uint32(i), v)
+	}
+
+	require.NoError(t, strict.SetBucketSize(8))
+	for i := 4; i < 8; i++ {
+		require.True(t, strict.Put(Key(i*64), uint32(i)))
+	}
+	require.NoError(t, strict.Validate())
+
+	// the cluster no longer fits into smaller neighborhoods
+	require.ErrorIs(t, strict.SetBucketSize(4), hopmap.ErrResizeTooSmall)
+	require.Equal(t, 8, strict.Config().BucketSize)
+	require.Equal(t, 8, strict.Len())
+	require.NoError(t, strict.Validate())
+
+	require.ErrorIs(t, strict.SetBucketSize(33), hopmap.ErrInvalidConfig)
+}
+
+func TestEnsureSlot(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: 4, BucketSize: 4, AutoResize: true, MaxLoad: 0.75})
+
+	p, created := m.EnsureSlot(1)
+	require.True(t, created)
+	require.Zero(t, *p)
+	require.Equal(t, 1, m.Len())
+
+	// the slot can be filled later, even after the map grows
+	for i := 2; i < 20; i++ {
+		m.Put(Key(i), uint32(i))
+	}
+	*p = 42
+	v, ok := m.Get(1)
+	require.True(t, ok)
+	require.Equal(t, uint32(42), v)
+
+	q, created := m.EnsureSlot(1)
+	require.False(t, created)
+	require.Same(t, p, q)
+
+	full := hopmap.New[Key, uint32](hopmap.Config{Size: 2, BucketSize: 2})
+	full.Put(0, 0)
+	full.Put(1, 1)
+	p, created = full.EnsureSlot(2)
+	require.Nil(t, p)
+	require.False(t, created)
+	require.Equal(t, 2, full.Len())
+}
+
+func TestPin(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: 64, BucketSize: 8})
+	for i := 0; i < 10; i++ {
+		m.Put(Key(i), uint32(i))
+	}
+
+	require.False(t, m.IsPinned(3))
+	require.False(t, m.Pin(42))
+	require.True(t, m.Pin(3))
+	require.True(t, m.Pin(4))
+	require.True(t, m.IsPinned(3))
+
+	c := m.Clone()
+	require.True(t, m.Unpin(4))
+	require.False(t, m.Unpin(4))
+	require.False(t, m.IsPinned(4))
+	require.True(t, c.IsPinned(4))
+
+	// pins survive resizes, and deleting a key drops its pin
+	require.NoError(t, m.Resize(16))
+	require.True(t, m.IsPinned(3))
+	m.Delete(3)
+	m.Put(3, 3)
+	require.False(t, m.IsPinned(3))
+}
+
+func TestPinFull(t *testing.T) {
+	m := hopmap.New[constKey, int](hopmap.Config{Size: 64, BucketSize: 16})
+	for i := 0; i < 9; i++ {
+		require.True(t, m.Put(constKey(i), i))
+	}
+
+	// pins are stored in a map of their own, whose neighborhoods hold 8 keys
+	for i := 0; i < 8; i++ {
+		require.True(t, m.Pin(constKey(i)))
+	}
+	require.False(t, m.Pin(8))
+	require.False(t, m.IsPinned(8))
+}
+
+func TestPutUntilLoad(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: 64, BucketSize: 8, AutoResize: true, MaxLoad: 0.9})
+	m.Put(0, 0)
+
+	pairs := make([]hopmap.Pair[Key, uint32], 100)
+	for i := range pairs {
+		pairs[i] = hopmap.Pair[Key, uint32]{Key: Key(i), Value: uint32(i + 1)}
+	}
+
+	// key 0 is overwritten without counting towards the load
+	n := m.PutUntilLoad(pairs, 0.5)
+	require.Equal(t, 32, n)
+	require.Equal(t, 32, m.Len())
+	require.Equal(t, 64, m.Size())
+	require.Equal(t, 0.5, m.Load())
+
+	v, _ := m.Get(0)
+	require.Equal(t, uint32(1), v)
+	_, ok := m.Get(Key(n))
+	require.False(t, ok)
+
+	require.Zero(t, m.PutUntilLoad(pairs[n:], 0.5))
+	require.Equal(t, n, m.PutUntilLoad(pairs[:n], 0.5))
+}
+
+func TestOnReshift(t *testing.T) {
+	var moves [][2]int
+	m := hopmap.New[Key, uint32](hopmap.Config{
+		Size:       64,
+		BucketSize: 4,
+		Stats:      true,
+		OnReshift: func(from, to int) {
+			moves = append(moves, [2]int{from, to})
+		},
+	})
+
+	// a full run of slots forces a key homed in bucket 0 to hop entries out of its way
+	for i := 0; i < 8; i++ {
+		require.True(t, m.Put(Key(i), uint32(i)))
+	}
+	require.Empty(t, moves)
+
+	// the empty slot 8 travels back to slot 3, one entry at a time
+	require.True(t, m.Put(64, 64))
+	require.Equal(t, [][2]int{{7, 8}, {6, 7}, {5, 6}, {4, 5}, {3, 4}}, moves)
+	require.Equal(t, uint64(len(moves)), m.Stats().Reshifts)
+	require.NoError(t, m.Validate())
+}
+
+func TestCanFit(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: 256, BucketSize: 8})
+	require.True(t, m.CanFit(0))
+	require.True(t, m.CanFit(8))
+	require.False(t, m.CanFit(9))
+
+	r := rand.New(rand.NewSource(1))
+	for m.Put(Key(r.Uint32()), 0) {
+		// the prediction must hold for every amount it allows
+		for n := 1; m.CanFit(n); n++ {
+			c := m.Clone()
+			for i := 0; i < n; i++ {
+				require.True(t, c.Put(Key(r.Uint32()), 0))
+			}
+		}
+	}
+	require.False(t, m.CanFit(1))
+
+	resizing := hopmap.New[Key, uint32](hopmap.Config{Size: 64, BucketSize: 32, AutoResize: true, MaxLoad: 0.5})
+	require.True(t, resizing.CanFit(32))
+	require.False(t, resizing.CanFit(33))
+}
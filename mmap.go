@@ -0,0 +1,242 @@
+package hopmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// The mmap format lays out a hopscotch table of byte keys and values, queried in place by MmapMap:
+//
+//	header:    magic "HOPM", version, BucketSize (uint32 each), padding (uint32), Size, Len (uint64 each)
+//	neighbors: Size neighbor bitmaps (uint32 each)
+//	slots:     Size offsets of the record held by each slot, or zero for empty slots (uint64 each)
+//	records:   key length, value length (uint32 each), key bytes, value bytes
+//	trailer:   CRC-32C of everything before it (uint32)
+//
+// All integers are little endian. Home buckets are computed with stableHash,
+// so that files can be queried by other processes than the one writing them.
+const (
+	mmapMagic      = "HOPM"
+	mmapVersion    = 2
+	mmapHeaderSize = 32
+)
+
+var mmapTable = crc32.MakeTable(crc32.Castagnoli)
+
+// stableBytes is a byte key whose hash code does not depend on the process, unlike Bytes.
+type stableBytes []byte
+
+func (x stableBytes) Equals(y stableBytes) bool {
+	return bytes.Equal(x, y)
+}
+
+func (x stableBytes) HashCode() uint32 {
+	return stableHash(x)
+}
+
+// stableHash is FNV-1a, finalized to spread its bits.
+func stableHash(b []byte) uint32 {
+	h := uint32(2166136261)
+	for _, c := range b {
+		h = (h ^ uint32(c)) * 16777619
+	}
+	return fmix32(h)
+}
+
+// WriteMmap writes the entries of m to w in a format which MmapMap queries in place,
+// encoding keys and values with the given functions, and returns the number of bytes written.
+// Encoded keys must be distinct. The table is laid out anew, starting from the size of m.
+func WriteMmap[K Hashable[K], V any](w io.Writer, m *Map[K, V], encodeKey func(K) []byte, encodeValue func(V) []byte) (int64, error) {
+	var keys, values [][]byte
+	t := New[stableBytes, int](Config{Size: m.size, BucketSize: m.config.BucketSize, AutoResize: true})
+
+	var err error
+	m.Range(func(k K, v V) bool {
+		kb := encodeKey(k)
+		if err = t.PutUnique(kb, len(keys)); err != nil {
+			err = fmt.Errorf("hopmap: encoding key %v: %w", k, err)
+			return false
+		}
+		keys, values = append(keys, kb), append(values, encodeValue(v))
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, mmapHeaderSize+12*t.size)
+	copy(buf, mmapMagic)
+	binary.LittleEndian.PutUint32(buf[4:], mmapVersion)
+	binary.LittleEndian.PutUint32(buf[8:], uint32(t.config.BucketSize))
+	binary.LittleEndian.PutUint64(buf[16:], uint64(t.size))
+	binary.LittleEndian.PutUint64(buf[24:], uint64(t.n))
+
+	neighbors, slots := buf[mmapHeaderSize:], buf[mmapHeaderSize+4*t.size:]
+	for i, nb := range t.neighbors {
+		binary.LittleEndian.PutUint32(neighbors[4*i:], nb)
+	}
+
+	// records follow the order of slots, so that neighboring slots have neighboring records
+	offset := uint64(len(buf))
+	order := make([]int, 0, t.n)
+	t.forEachPresent(func(j int) bool {
+		i := t.entries[j].value
+		binary.LittleEndian.PutUint64(slots[8*j:], offset)
+		offset += 8 + uint64(len(keys[i])) + uint64(len(values[i]))
+		order = append(order, i)
+		return true
+	})
+
+	crc := crc32.New(mmapTable)
+	w = io.MultiWriter(w, crc)
+
+	n, err := w.Write(buf)
+	written := int64(n)
+	for _, i := range order {
+		if err != nil {
+			break
+		}
+
+		var lengths [8]byte
+		binary.LittleEndian.PutUint32(lengths[:], uint32(len(keys[i])))
+		binary.LittleEndian.PutUint32(lengths[4:], uint32(len(values[i])))
+		for _, b := range [][]byte{lengths[:], keys[i], values[i]} {
+			if n, err = w.Write(b); err != nil {
+				break
+			}
+			written += int64(n)
+		}
+	}
+	if err == nil {
+		err = binary.Write(w, binary.LittleEndian, crc.Sum32())
+		written += 4
+	}
+	return written, err
+}
+
+// MmapMap is a read-only map of byte keys to byte values, queried in place over data written by WriteMmap,
+// such as a memory-mapped file, so that huge precomputed maps are served without loading them on the heap.
+type MmapMap struct {
+	data       []byte
+	neighbors  []byte
+	slots      []byte
+	size       uint32
+	bucketSize int
+	n          int
+}
+
+// OpenMmap checks that data holds a table written by WriteMmap, and returns a map reading from it.
+// The layout is checked, but not the checksum, which would read every record.
+// The data must not be modified while the map is in use.
+func OpenMmap(data []byte) (*MmapMap, error) {
+	if len(data) < mmapHeaderSize+4 || string(data[:4]) != mmapMagic {
+		return nil, fmt.Errorf("%w: missing mmap header", ErrCorrupted)
+	}
+	if v := binary.LittleEndian.Uint32(data[4:]); v != mmapVersion {
+		return nil, fmt.Errorf("%w: unsupported mmap version %d", ErrCorrupted, v)
+	}
+
+	bucketSize := binary.LittleEndian.Uint32(data[8:])
+	size := binary.LittleEndian.Uint64(data[16:])
+	n := binary.LittleEndian.Uint64(data[24:])
+	if bucketSize == 0 || bucketSize > 32 || size == 0 || size > MaxSize || n > size ||
+		uint64(len(data)-mmapHeaderSize-4)/12 < size {
+		return nil, fmt.Errorf("%w: invalid mmap header", ErrCorrupted)
+	}
+
+	m := &MmapMap{
+		data:       data,
+		neighbors:  data[mmapHeaderSize : mmapHeaderSize+4*size],
+		slots:      data[mmapHeaderSize+4*size : mmapHeaderSize+12*size],
+		size:       uint32(size),
+		bucketSize: int(bucketSize),
+		n:          int(n),
+	}
+
+	// check the records once, so that lookups can trust them
+	limit := uint64(len(data)) - 4 // records end before the trailer
+	records := 0
+	for j := uint32(0); j < m.size; j++ {
+		offset := binary.LittleEndian.Uint64(m.slots[8*j:])
+		if offset == 0 {
+			continue
+		}
+		if offset < mmapHeaderSize+12*size || offset > limit-8 {
+			return nil, fmt.Errorf("%w: slot %d refers to offset %d out of range", ErrCorrupted, j, offset)
+		}
+		if end := offset + 8 + uint64(binary.LittleEndian.Uint32(data[offset:])) +
+			uint64(binary.LittleEndian.Uint32(data[offset+4:])); end > limit {
+			return nil, fmt.Errorf("%w: record of slot %d ends at offset %d out of range", ErrCorrupted, j, end)
+		}
+		records++
+	}
+	if records != m.n {
+		return nil, fmt.Errorf("%w: %d records, but Len() is %d", ErrCorrupted, records, m.n)
+	}
+	return m, nil
+}
+
+// verifyMmap checks the trailing checksum of data written by WriteMmap.
+func verifyMmap(data []byte) error {
+	if len(data) < mmapHeaderSize+4 {
+		return fmt.Errorf("%w: mmap data of %d bytes is too short", ErrCorrupted, len(data))
+	}
+
+	body := data[:len(data)-4]
+	sum, want := binary.LittleEndian.Uint32(data[len(body):]), crc32.Checksum(body, mmapTable)
+	if sum != want {
+		return fmt.Errorf("%w: checksum %#x, but content sums to %#x", ErrCorrupted, sum, want)
+	}
+	return nil
+}
+
+// Get returns the value of key, which aliases the underlying data.
+func (m *MmapMap) Get(key []byte) ([]byte, bool) {
+	hash := stableHash(key) % m.size
+
+	nb := binary.LittleEndian.Uint32(m.neighbors[4*hash:])
+	for j := hash; nb != 0; nb, j = nb<<1, (j+1)%m.size {
+		if nb&(1<<31) == 0 {
+			continue
+		}
+
+		if k, v, ok := m.record(j); ok && bytes.Equal(k, key) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func (m *MmapMap) Len() int {
+	return m.n
+}
+
+func (m *MmapMap) Size() int {
+	return int(m.size)
+}
+
+// Range calls fn for each entry, by ascending slot, stopping as soon as fn returns false.
+// Keys and values alias the underlying data.
+func (m *MmapMap) Range(fn func(key, value []byte) bool) {
+	for j := uint32(0); j < m.size; j++ {
+		if k, v, ok := m.record(j); ok && !fn(k, v) {
+			return
+		}
+	}
+}
+
+// record returns the key and value held by slot j, capped so that appending to them cannot overwrite the data.
+func (m *MmapMap) record(j uint32) ([]byte, []byte, bool) {
+	offset := binary.LittleEndian.Uint64(m.slots[8*j:])
+	if offset == 0 {
+		return nil, nil, false
+	}
+
+	kl := uint64(binary.LittleEndian.Uint32(m.data[offset:]))
+	vl := uint64(binary.LittleEndian.Uint32(m.data[offset+4:]))
+	k, v := offset+8, offset+8+kl
+	return m.data[k:v:v], m.data[v : v+vl : v+vl], true
+}
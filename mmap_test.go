@@ -0,0 +1,105 @@
+package hopmap_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeKey(k Key) []byte {
+	return binary.LittleEndian.AppendUint32(nil, uint32(k))
+}
+
+func encodeValue(v string) []byte {
+	return []byte(v)
+}
+
+// writeMmapFile writes the entries of n keys, and of four more overflowing ones, to a file in a temporary directory.
+func writeMmapFile(t *testing.T, n int) string {
+	m := hopmap.New[Key, string](hopmap.Config{Size: 2048, BucketSize: 4, AllowOverflow: true})
+	for i := 0; i < n; i++ {
+		require.True(t, m.Put(Key(i), strconv.Itoa(i)))
+	}
+	for i := 1; i <= 4; i++ {
+		require.True(t, m.Put(Key(i*2048), "far"+strconv.Itoa(i)))
+	}
+
+	var buf bytes.Buffer
+	written, err := hopmap.WriteMmap(&buf, m, encodeKey, encodeValue)
+	require.NoError(t, err)
+	require.Equal(t, int64(buf.Len()), written)
+
+	path := filepath.Join(t.TempDir(), "map.hopm")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+	return path
+}
+
+func checkMmapMap(t *testing.T, m *hopmap.MmapMap, n int) {
+	require.Equal(t, n+4, m.Len())
+	for i := 0; i < n; i++ {
+		v, ok := m.Get(encodeKey(Key(i)))
+		require.True(t, ok)
+		require.Equal(t, strconv.Itoa(i), string(v))
+	}
+	for i := 1; i <= 4; i++ {
+		v, ok := m.Get(encodeKey(Key(i * 2048)))
+		require.True(t, ok)
+		require.Equal(t, "far"+strconv.Itoa(i), string(v))
+	}
+
+	_, ok := m.Get(encodeKey(Key(n)))
+	require.False(t, ok)
+	_, ok = m.Get(nil)
+	require.False(t, ok)
+
+	count := 0
+	m.Range(func(k, v []byte) bool {
+		count++
+		require.Len(t, k, 4)
+		return true
+	})
+	require.Equal(t, m.Len(), count)
+}
+
+func TestMmapMap(t *testing.T) {
+	data, err := os.ReadFile(writeMmapFile(t, 1000))
+	require.NoError(t, err)
+
+	m, err := hopmap.OpenMmap(data)
+	require.NoError(t, err)
+	checkMmapMap(t, m, 1000)
+
+	// values alias the data, without letting appends overwrite it
+	v, _ := m.Get(encodeKey(7))
+	_ = append(v, '!')
+	v, _ = m.Get(encodeKey(7))
+	require.Equal(t, "7", string(v))
+
+	_, err = hopmap.OpenMmap(data[:len(data)-1])
+	require.ErrorIs(t, err, hopmap.ErrCorrupted)
+	_, err = hopmap.OpenMmap(data[:20])
+	require.ErrorIs(t, err, hopmap.ErrCorrupted)
+	_, err = hopmap.OpenMmap(append([]byte("JUNK"), data[4:]...))
+	require.ErrorIs(t, err, hopmap.ErrCorrupted)
+
+	empty := hopmap.New[Key, string](hopmap.Config{Size: 16, BucketSize: 8})
+	var buf bytes.Buffer
+	_, err = hopmap.WriteMmap(&buf, empty, encodeKey, encodeValue)
+	require.NoError(t, err)
+	m, err = hopmap.OpenMmap(buf.Bytes())
+	require.NoError(t, err)
+	require.Zero(t, m.Len())
+
+	// distinct keys must have distinct encodings
+	dup := hopmap.New[Key, string](hopmap.Config{Size: 16, BucketSize: 8})
+	dup.Put(1, "a")
+	dup.Put(2, "b")
+	_, err = hopmap.WriteMmap(&buf, dup, func(Key) []byte { return nil }, encodeValue)
+	require.ErrorIs(t, err, hopmap.ErrDuplicateKey)
+}
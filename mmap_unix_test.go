@@ -0,0 +1,29 @@
+//go:build unix
+
+package hopmap_test
+
+import (
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMmapMapMapped(t *testing.T) {
+	f, err := os.Open(writeMmapFile(t, 1000))
+	require.NoError(t, err)
+	defer f.Close()
+
+	info, err := f.Stat()
+	require.NoError(t, err)
+
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	require.NoError(t, err)
+	defer syscall.Munmap(data)
+
+	m, err := hopmap.OpenMmap(data)
+	require.NoError(t, err)
+	checkMmapMap(t, m, 1000)
+}
@@ -0,0 +1,112 @@
+package hopmap
+
+// NestedConfig configures a NestedMap.
+type NestedConfig struct {
+	// Config configures the outer map.
+	Config
+	// Inner configures the inner maps, each created on the first insertion under its outer key.
+	Inner Config
+	// PruneEmpty makes Delete remove an inner map once its last key is deleted.
+	PruneEmpty bool
+}
+
+// NestedMap maps pairs of keys to values through two levels of maps,
+// so that all the entries sharing an outer key can be accessed, or removed, at once.
+type NestedMap[K1 Hashable[K1], K2 Hashable[K2], V any] struct {
+	m     *Map[K1, *Map[K2, V]]
+	inner Config
+	prune bool
+	n     int
+}
+
+func NewNested[K1 Hashable[K1], K2 Hashable[K2], V any](c NestedConfig) *NestedMap[K1, K2, V] {
+	return &NestedMap[K1, K2, V]{
+		m:     New[K1, *Map[K2, V]](c.Config),
+		inner: c.Inner,
+		prune: c.PruneEmpty,
+	}
+}
+
+func (nm *NestedMap[K1, K2, V]) Get(k1 K1, k2 K2) (V, bool) {
+	inner, ok := nm.m.Get(k1)
+	if !ok {
+		return zeroValue[V](), false
+	}
+	return inner.Get(k2)
+}
+
+// Put stores value under the pair of keys, creating the inner map of k1 if needed.
+func (nm *NestedMap[K1, K2, V]) Put(k1 K1, k2 K2, value V) bool {
+	inner, found := nm.m.Get(k1)
+	if !found {
+		inner = New[K2, V](nm.inner)
+		if !nm.m.Put(k1, inner) {
+			return false
+		}
+	}
+
+	n := inner.Len()
+	if !inner.Put(k2, value) {
+		if !found {
+			nm.m.Delete(k1)
+		}
+		return false
+	}
+	nm.n += inner.Len() - n
+	return true
+}
+
+// Delete removes the pair of keys, and the inner map of k1 if it is left empty and PruneEmpty is set.
+func (nm *NestedMap[K1, K2, V]) Delete(k1 K1, k2 K2) (V, bool) {
+	inner, ok := nm.m.Get(k1)
+	if !ok {
+		return zeroValue[V](), false
+	}
+
+	value, ok := inner.Delete(k2)
+	if !ok {
+		return zeroValue[V](), false
+	}
+	nm.n--
+
+	if nm.prune && inner.Len() == 0 {
+		nm.m.Delete(k1)
+	}
+	return value, true
+}
+
+// Inner returns the inner map of k1. Changing it directly leaves Len out of date.
+func (nm *NestedMap[K1, K2, V]) Inner(k1 K1) (*Map[K2, V], bool) {
+	return nm.m.Get(k1)
+}
+
+// DeleteOuter removes all the entries under k1, returning their inner map.
+func (nm *NestedMap[K1, K2, V]) DeleteOuter(k1 K1) (*Map[K2, V], bool) {
+	inner, ok := nm.m.Delete(k1)
+	if ok {
+		nm.n -= inner.Len()
+	}
+	return inner, ok
+}
+
+// Len returns the number of pairs of keys in the map.
+func (nm *NestedMap[_, _, _]) Len() int {
+	return nm.n
+}
+
+// OuterLen returns the number of outer keys, including those whose inner map is empty.
+func (nm *NestedMap[_, _, _]) OuterLen() int {
+	return nm.m.Len()
+}
+
+// Range calls fn for each entry, grouped by outer key, stopping as soon as fn returns false.
+func (nm *NestedMap[K1, K2, V]) Range(fn func(K1, K2, V) bool) {
+	nm.m.Range(func(k1 K1, inner *Map[K2, V]) bool {
+		ok := true
+		inner.Range(func(k2 K2, v V) bool {
+			ok = fn(k1, k2, v)
+			return ok
+		})
+		return ok
+	})
+}
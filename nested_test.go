@@ -0,0 +1,77 @@
+package hopmap_test
+
+import (
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNestedMap(t *testing.T) {
+	for _, prune := range []bool{false, true} {
+		m := hopmap.NewNested[Key, Key, int](hopmap.NestedConfig{
+			Config:     hopmap.Config{Size: 16, BucketSize: 8, AutoResize: true, MaxLoad: 0.75},
+			Inner:      hopmap.Config{Size: 4, BucketSize: 4, AutoResize: true, MaxLoad: 0.75},
+			PruneEmpty: prune,
+		})
+
+		// inner maps are created on the first insertion under each outer key
+		for u := 0; u < 10; u++ {
+			for s := 0; s < u; s++ {
+				require.True(t, m.Put(Key(u), Key(s), u*100+s))
+			}
+		}
+		require.Equal(t, 45, m.Len())
+		require.Equal(t, 9, m.OuterLen())
+
+		v, ok := m.Get(7, 3)
+		require.True(t, ok)
+		require.Equal(t, 703, v)
+		_, ok = m.Get(3, 7)
+		require.False(t, ok)
+		_, ok = m.Get(0, 0)
+		require.False(t, ok)
+
+		inner, ok := m.Inner(9)
+		require.True(t, ok)
+		require.Equal(t, 9, inner.Len())
+
+		// overwrites do not count as new pairs
+		require.True(t, m.Put(7, 3, 0))
+		require.Equal(t, 45, m.Len())
+
+		// emptying an inner map prunes it only if requested
+		v, ok = m.Delete(1, 0)
+		require.True(t, ok)
+		require.Equal(t, 100, v)
+		_, ok = m.Delete(1, 0)
+		require.False(t, ok)
+		_, ok = m.Inner(1)
+		require.Equal(t, !prune, ok)
+		require.Equal(t, 44, m.Len())
+
+		inner, ok = m.DeleteOuter(9)
+		require.True(t, ok)
+		require.Equal(t, 9, inner.Len())
+		require.Equal(t, 35, m.Len())
+		_, ok = m.Get(9, 0)
+		require.False(t, ok)
+
+		count := 0
+		m.Range(func(u, s Key, v int) bool {
+			if u != 7 || s != 3 {
+				require.Equal(t, int(u)*100+int(s), v)
+			}
+			count++
+			return true
+		})
+		require.Equal(t, m.Len(), count)
+
+		count = 0
+		m.Range(func(Key, Key, int) bool {
+			count++
+			return count < 5
+		})
+		require.Equal(t, 5, count)
+	}
+}
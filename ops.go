@@ -0,0 +1,165 @@
+package hopmap
+
+// GroupBy builds a new map with the same config as m, storing each entry under keyFn(key, value).
+// Values of entries mapped to the same key are merged through combine.
+func GroupBy[K Hashable[K], V any, GK Hashable[GK]](m *Map[K, V], keyFn func(K, V) GK, combine func(a, b V) V) *Map[GK, V] {
+	g := New[GK, V](m.config.derive())
+	m.Range(func(k K, v V) bool {
+		gk := keyFn(k, v)
+
+		if e := g.findEntry(g.hashKey(gk), gk); e >= 0 {
+			g.overwrite(&g.entries[e], combine(g.entries[e].value, v))
+		} else {
+			g.mustInsert(g.newEntry(gk, v))
+		}
+		return true
+	})
+	return g
+}
+
+// AppendTo appends vals to the slice stored under key, or stores a copy of vals if key is absent.
+// The slice is updated through its entry with a single lookup, avoiding the round trip of Get and Put.
+// It returns false if key is absent and cannot be inserted.
+func AppendTo[K Hashable[K], V any](m *Map[K, []V], key K, vals ...V) bool {
+	hash := m.hashKey(key)
+	if e := m.lookup(hash, key); e != nil {
+		m.overwrite(e, append((*e).value, vals...))
+		return true
+	}
+	return m.putNew(hash, key, append([]V(nil), vals...)) == nil
+}
+
+// InnerJoin builds a map holding the keys present in both a and b, each paired with its values.
+// The smaller map is iterated, probing the larger one, and its config is used for the result.
+func InnerJoin[K Hashable[K], V, W any](a *Map[K, V], b *Map[K, W]) *Map[K, Pair[V, W]] {
+	if a.Len() <= b.Len() {
+		j := New[K, Pair[V, W]](a.config.derive())
+		a.forEach(func(e *entry[K, V]) bool {
+			if f := b.lookup(b.hashKey(e.key), e.key); f != nil {
+				j.mustInsert(j.newEntry(e.key, Pair[V, W]{e.value, (*f).value}))
+			}
+			return true
+		})
+		return j
+	}
+
+	j := New[K, Pair[V, W]](b.config.derive())
+	b.forEach(func(e *entry[K, W]) bool {
+		if f := a.lookup(a.hashKey(e.key), e.key); f != nil {
+			j.mustInsert(j.newEntry(e.key, Pair[V, W]{(*f).value, e.value}))
+		}
+		return true
+	})
+	return j
+}
+
+// Joined is a value of a LeftJoin: L is the value from the left map, and R the one
+// from the right map, if HasR is set.
+type Joined[V, W any] struct {
+	L    V
+	R    W
+	HasR bool
+}
+
+// LeftJoin builds a map holding every key of a, pairing its value with the one in b, if any.
+// The result uses the config of a.
+func LeftJoin[K Hashable[K], V, W any](a *Map[K, V], b *Map[K, W]) *Map[K, Joined[V, W]] {
+	j := New[K, Joined[V, W]](a.config.derive())
+	a.forEach(func(e *entry[K, V]) bool {
+		v := Joined[V, W]{L: e.value}
+		if f := b.lookup(b.hashKey(e.key), e.key); f != nil {
+			v.R, v.HasR = (*f).value, true
+		}
+		j.mustInsert(j.newEntry(e.key, v))
+		return true
+	})
+	return j
+}
+
+// IntersectionCount returns how many keys are in both a and b, without allocating.
+// The smaller map is iterated, probing the larger one.
+func IntersectionCount[K Hashable[K], V, W any](a *Map[K, V], b *Map[K, W]) int {
+	if a.Len() <= b.Len() {
+		return countShared(a, b)
+	}
+	return countShared(b, a)
+}
+
+func countShared[K Hashable[K], V, W any](iterated *Map[K, V], probed *Map[K, W]) int {
+	n := 0
+	iterated.forEach(func(e *entry[K, V]) bool {
+		if probed.lookup(probed.hashKey(e.key), e.key) != nil {
+			n++
+		}
+		return true
+	})
+	return n
+}
+
+// DeleteKeysIn deletes from m every key which is also in other, and returns how many keys were deleted.
+// The smaller map is iterated, probing the larger one.
+func DeleteKeysIn[K Hashable[K], V, W any](m *Map[K, V], other *Map[K, W]) int {
+	if m.Len() > other.Len() {
+		n := 0
+		other.forEach(func(e *entry[K, W]) bool {
+			if _, ok := m.Delete(e.key); ok {
+				n++
+			}
+			return true
+		})
+		return n
+	}
+	return deleteByPresence(m, other, true)
+}
+
+// RetainKeysIn deletes from m every key which is not in other, and returns how many keys were deleted.
+// It scans m, probing other.
+func RetainKeysIn[K Hashable[K], V, W any](m *Map[K, V], other *Map[K, W]) int {
+	return deleteByPresence(m, other, false)
+}
+
+// deleteByPresence scans m, deleting the keys which are in other if inOther is set,
+// or which are not in other otherwise, and returns how many keys were deleted.
+func deleteByPresence[K Hashable[K], V, W any](m *Map[K, V], other *Map[K, W], inOther bool) int {
+	n := 0
+	m.RangeMut(func(k K, _ *V) Action {
+		if (other.lookup(other.hashKey(k), k) != nil) != inOther {
+			return Keep
+		}
+		n++
+		return Delete
+	})
+	// RangeMut leaves the size unchanged, while Delete may have shrunk the map several times
+	for m.shouldShrink() && m.rehash(m.size/2) {
+	}
+	return n
+}
+
+// Equal reports whether a and b hold the same keys, with values equal according to valueEq,
+// regardless of where entries are placed.
+func Equal[K Hashable[K], V any](a, b *Map[K, V], valueEq func(V, V) bool) bool {
+	if a.Len() != b.Len() {
+		return false
+	}
+
+	eq := true
+	a.forEach(func(e *entry[K, V]) bool {
+		f := b.lookup(b.hashKey(e.key), e.key)
+		eq = f != nil && valueEq(e.value, (*f).value)
+		return eq
+	})
+	return eq
+}
+
+// Checksum combines the hashes of all the entries into a value independent of their placement
+// and order, so that maps with different checksums are known to differ without calling Equal.
+// Equal maps always have the same checksum, while the converse only holds with high probability.
+func (m *Map[K, V]) Checksum(keyHash func(K) uint64, valHash func(V) uint64) uint64 {
+	var sum uint64
+	m.forEach(func(e *entry[K, V]) bool {
+		// the value hash is mixed before being combined, so that swapping a key and its value matters
+		sum += fmix64(keyHash(e.key) ^ fmix64(valHash(e.value)))
+		return true
+	})
+	return sum
+}
@@ -0,0 +1,243 @@
+package hopmap_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupBy(t *testing.T) {
+	m := hopmap.New[Key, int](hopmap.Config{Size: 64, BucketSize: 8})
+	for i := 1; i <= 10; i++ {
+		m.Put(Key(i), i)
+	}
+
+	g := hopmap.GroupBy(m,
+		func(k Key, v int) Key { return k % 2 },
+		func(a, b int) int { return a + b },
+	)
+	require.Equal(t, 2, g.Len())
+
+	even, _ := g.Get(0)
+	odd, _ := g.Get(1)
+	require.Equal(t, 30, even)
+	require.Equal(t, 25, odd)
+}
+
+func TestInnerJoin(t *testing.T) {
+	a := hopmap.New[Key, int](hopmap.Config{Size: 64, BucketSize: 8})
+	for i := 0; i < 20; i++ {
+		a.Put(Key(i), i)
+	}
+
+	b := hopmap.New[Key, string](hopmap.Config{Size: 16, BucketSize: 8})
+	for i := 15; i < 25; i++ {
+		b.Put(Key(i), strconv.Itoa(i))
+	}
+
+	j := hopmap.InnerJoin(a, b)
+	require.Equal(t, 5, j.Len())
+	for i := 0; i < 25; i++ {
+		p, ok := j.Get(Key(i))
+		require.Equal(t, i >= 15 && i < 20, ok)
+		if ok {
+			require.Equal(t, hopmap.Pair[int, string]{Key: i, Value: strconv.Itoa(i)}, p)
+		}
+	}
+
+	// iterating the smaller map must not swap values
+	r := hopmap.InnerJoin(b, a)
+	require.Equal(t, 5, r.Len())
+	p, ok := r.Get(17)
+	require.True(t, ok)
+	require.Equal(t, hopmap.Pair[string, int]{Key: "17", Value: 17}, p)
+}
+
+func TestLeftJoin(t *testing.T) {
+	a := hopmap.New[Key, int](hopmap.Config{Size: 64, BucketSize: 8})
+	for i := 0; i < 20; i++ {
+		a.Put(Key(i), i)
+	}
+
+	b := hopmap.New[Key, string](hopmap.Config{Size: 16, BucketSize: 8})
+	for i := 15; i < 25; i++ {
+		b.Put(Key(i), strconv.Itoa(i))
+	}
+
+	j := hopmap.LeftJoin(a, b)
+	require.Equal(t, a.Len(), j.Len())
+
+	v, ok := j.Get(3)
+	require.True(t, ok)
+	require.Equal(t, hopmap.Joined[int, string]{L: 3}, v)
+
+	v, ok = j.Get(17)
+	require.True(t, ok)
+	require.Equal(t, hopmap.Joined[int, string]{L: 17, R: "17", HasR: true}, v)
+
+	_, ok = j.Get(22)
+	require.False(t, ok)
+}
+
+func TestIntersectionCount(t *testing.T) {
+	a := hopmap.New[Key, int](hopmap.Config{Size: 64, BucketSize: 8})
+	for i := 0; i < 20; i++ {
+		a.Put(Key(i), i)
+	}
+
+	b := hopmap.New[Key, string](hopmap.Config{Size: 16, BucketSize: 8})
+	for i := 15; i < 25; i++ {
+		b.Put(Key(i), strconv.Itoa(i))
+	}
+
+	require.Equal(t, 5, hopmap.IntersectionCount(a, b))
+	require.Equal(t, 5, hopmap.IntersectionCount(b, a))
+	require.Equal(t, hopmap.InnerJoin(a, b).Len(), hopmap.IntersectionCount(a, b))
+
+	empty := hopmap.New[Key, int](hopmap.Config{Size: 16, BucketSize: 8})
+	require.Zero(t, hopmap.IntersectionCount(a, empty))
+	require.Equal(t, a.Len(), hopmap.IntersectionCount(a, a))
+}
+
+func TestDeleteKeysIn(t *testing.T) {
+	build := func() *hopmap.Map[Key, int] {
+		m := hopmap.New[Key, int](hopmap.Config{Size: 64, BucketSize: 8, AllowOverflow: true})
+		for i := 0; i < 20; i++ {
+			m.Put(Key(i), i)
+		}
+		return m
+	}
+
+	small := hopmap.New[Key, string](hopmap.Config{Size: 16, BucketSize: 8})
+	for i := 15; i < 25; i++ {
+		small.Put(Key(i), strconv.Itoa(i))
+	}
+	large := hopmap.New[Key, string](hopmap.Config{Size: 64, BucketSize: 8})
+	for i := 15; i < 55; i++ {
+		large.Put(Key(i), strconv.Itoa(i))
+	}
+
+	// the other map is iterated when smaller, and m otherwise
+	for name, other := range map[string]*hopmap.Map[Key, string]{"smaller": small, "larger": large} {
+		t.Run(name, func(t *testing.T) {
+			m := build()
+			require.Equal(t, 5, hopmap.DeleteKeysIn(m, other))
+			require.Equal(t, 15, m.Len())
+			for i := 0; i < 20; i++ {
+				_, ok := m.Get(Key(i))
+				require.Equal(t, i < 15, ok)
+			}
+			require.Equal(t, 10, small.Len())
+			require.Equal(t, 40, large.Len())
+			require.Zero(t, hopmap.DeleteKeysIn(m, other))
+		})
+	}
+
+	m := build()
+	require.Zero(t, hopmap.DeleteKeysIn(m, hopmap.New[Key, string](hopmap.Config{Size: 16, BucketSize: 8})))
+	require.Equal(t, 20, hopmap.DeleteKeysIn(m, m))
+	require.Zero(t, m.Len())
+
+	// the map shrinks as if its keys were deleted one at a time
+	shrinking := hopmap.New[Key, int](hopmap.Config{Size: 64, BucketSize: 8, AutoResize: true, MinLoad: 0.25})
+	for i := 0; i < 40; i++ {
+		shrinking.Put(Key(i), i)
+	}
+	require.Equal(t, 25, hopmap.DeleteKeysIn(shrinking, large))
+	require.Less(t, shrinking.Size(), 64)
+	require.NoError(t, shrinking.Validate())
+}
+
+func TestRetainKeysIn(t *testing.T) {
+	m := hopmap.New[Key, int](hopmap.Config{Size: 64, BucketSize: 8, AllowOverflow: true})
+	for i := 0; i < 20; i++ {
+		m.Put(Key(i), i)
+	}
+	other := hopmap.New[Key, string](hopmap.Config{Size: 64, BucketSize: 8})
+	for i := 15; i < 55; i++ {
+		other.Put(Key(i), strconv.Itoa(i))
+	}
+
+	require.Equal(t, 15, hopmap.RetainKeysIn(m, other))
+	require.Equal(t, 5, m.Len())
+	m.Range(func(k Key, v int) bool {
+		require.GreaterOrEqual(t, k, Key(15))
+		require.Equal(t, int(k), v)
+		return true
+	})
+	require.Equal(t, 40, other.Len())
+	require.Zero(t, hopmap.RetainKeysIn(m, other))
+
+	require.Equal(t, 5, hopmap.RetainKeysIn(m, hopmap.New[Key, string](hopmap.Config{Size: 16, BucketSize: 8})))
+	require.Zero(t, m.Len())
+}
+
+func TestChecksum(t *testing.T) {
+	keyHash := func(k Key) uint64 { return uint64(k) }
+	valHash := func(v int) uint64 { return uint64(v) }
+
+	// the same entries, placed differently
+	a := hopmap.New[Key, int](hopmap.Config{Size: 64, BucketSize: 8})
+	b := hopmap.New[Key, int](hopmap.Config{Size: 256, BucketSize: 4, Seed: 7})
+	for i := 0; i < 40; i++ {
+		a.Put(Key(i), i)
+		b.Put(Key(39-i), 39-i)
+	}
+	require.Equal(t, a.Checksum(keyHash, valHash), b.Checksum(keyHash, valHash))
+
+	empty := hopmap.New[Key, int](hopmap.Config{Size: 16, BucketSize: 8})
+	require.Zero(t, empty.Checksum(keyHash, valHash))
+
+	sum := a.Checksum(keyHash, valHash)
+	b.Put(3, 4)
+	require.NotEqual(t, sum, b.Checksum(keyHash, valHash))
+
+	// swapping the values of two keys changes the checksum
+	b.Put(3, 5)
+	b.Put(5, 3)
+	require.NotEqual(t, sum, b.Checksum(keyHash, valHash))
+
+	b.Put(5, 5)
+	b.Put(3, 3)
+	require.Equal(t, sum, b.Checksum(keyHash, valHash))
+	b.Delete(3)
+	require.NotEqual(t, sum, b.Checksum(keyHash, valHash))
+}
+
+func TestAppendTo(t *testing.T) {
+	m := hopmap.New[Key, []int](hopmap.Config{Size: 16, BucketSize: 8})
+
+	vals := []int{1, 2}
+	require.True(t, hopmap.AppendTo(m, 1, vals...))
+	vals[0] = 0
+	require.True(t, hopmap.AppendTo(m, 1, 3))
+	require.True(t, hopmap.AppendTo(m, 2))
+
+	v, _ := m.Get(1)
+	require.Equal(t, []int{1, 2, 3}, v)
+	v, ok := m.Get(2)
+	require.True(t, ok)
+	require.Empty(t, v)
+	require.Equal(t, 2, m.Len())
+}
+
+func BenchmarkAppendTo(b *testing.B) {
+	const keys = 1 << 10
+
+	b.Run("AppendTo", func(b *testing.B) {
+		m := hopmap.New[Key, []int](hopmap.Config{Size: keys, BucketSize: 32})
+		for i := 0; i < b.N; i++ {
+			hopmap.AppendTo(m, Key(i%keys), i)
+		}
+	})
+
+	b.Run("GetAppendPut", func(b *testing.B) {
+		m := hopmap.New[Key, []int](hopmap.Config{Size: keys, BucketSize: 32})
+		for i := 0; i < b.N; i++ {
+			v, _ := m.Get(Key(i % keys))
+			m.Put(Key(i%keys), append(v, i))
+		}
+	})
+}
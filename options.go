@@ -0,0 +1,86 @@
+package hopmap
+
+import "fmt"
+
+// Option configures a Map built with NewOpts.
+type Option func(*Config) error
+
+// NewOpts creates a map starting from DefaultConfig and applying opts in order.
+// It panics if any option receives an invalid value.
+func NewOpts[K Hashable[K], V any](opts ...Option) *Map[K, V] {
+	c := DefaultConfig()
+	for _, opt := range opts {
+		if err := opt(&c); err != nil {
+			panic(err)
+		}
+	}
+	return New[K, V](c)
+}
+
+func WithSize(size int) Option {
+	return func(c *Config) error {
+		if size <= 0 || uint64(size) > MaxSize {
+			return fmt.Errorf("%w: size %d must be in [1, %d]", ErrInvalidConfig, size, uint64(MaxSize))
+		}
+		c.Size = size
+		return nil
+	}
+}
+
+func WithBucketSize(bucketSize int) Option {
+	return func(c *Config) error {
+		if bucketSize <= 0 || bucketSize > 32 {
+			return fmt.Errorf("%w: bucket size %d must be in [1, 32]", ErrInvalidConfig, bucketSize)
+		}
+		c.BucketSize = bucketSize
+		return nil
+	}
+}
+
+func WithAutoResize(enabled bool) Option {
+	return func(c *Config) error {
+		c.AutoResize = enabled
+		return nil
+	}
+}
+
+func WithSeed(seed uint32) Option {
+	return func(c *Config) error {
+		c.Seed = seed
+		return nil
+	}
+}
+
+// WithMaxLoad sets the load factor above which an auto-resizing map grows.
+func WithMaxLoad(load float64) Option {
+	return func(c *Config) error {
+		if !(load > 0 && load <= 1) {
+			return fmt.Errorf("%w: max load %v must be in (0, 1]", ErrInvalidConfig, load)
+		}
+		c.MaxLoad = load
+		return nil
+	}
+}
+
+func WithStats(enabled bool) Option {
+	return func(c *Config) error {
+		c.Stats = enabled
+		return nil
+	}
+}
+
+// WithOverflow enables the overflow set, with the given initial capacity and growth factor.
+func WithOverflow(capacity int, growth float64) Option {
+	return func(c *Config) error {
+		if capacity < 0 {
+			return fmt.Errorf("%w: overflow capacity %d must not be negative", ErrInvalidConfig, capacity)
+		}
+		if !(growth > 1) {
+			return fmt.Errorf("%w: overflow growth %v must be greater than 1", ErrInvalidConfig, growth)
+		}
+		c.AllowOverflow = true
+		c.OverflowCapacity = capacity
+		c.OverflowGrowth = growth
+		return nil
+	}
+}
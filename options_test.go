@@ -0,0 +1,51 @@
+package hopmap_test
+
+import (
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOpts(t *testing.T) {
+	m := hopmap.NewOpts[Key, uint32](
+		hopmap.WithSize(1<<4),
+		hopmap.WithBucketSize(8),
+		hopmap.WithAutoResize(true),
+		hopmap.WithMaxLoad(0.5),
+		hopmap.WithSeed(42),
+		hopmap.WithStats(true),
+	)
+	require.Equal(t, 1<<4, m.Size())
+
+	for i := 0; i < 100; i++ {
+		require.True(t, m.Put(Key(i), uint32(i)))
+	}
+	require.Equal(t, 100, m.Len())
+	require.LessOrEqual(t, m.Load(), 0.5)
+
+	for i := 0; i < 100; i++ {
+		v, ok := m.Get(Key(i))
+		require.True(t, ok)
+		require.Equal(t, uint32(i), v)
+	}
+
+	stats := m.Stats()
+	require.Equal(t, uint64(100), stats.Puts)
+	require.Equal(t, uint64(100), stats.Hits)
+	require.NotZero(t, stats.Resizes)
+}
+
+func TestNewOptsDefaults(t *testing.T) {
+	m := hopmap.NewOpts[Key, uint32]()
+	require.Equal(t, hopmap.DefaultConfig().Size, m.Size())
+
+	m.Put(1, 1)
+	require.Zero(t, m.Stats())
+}
+
+func TestNewOptsInvalid(t *testing.T) {
+	require.Panics(t, func() { hopmap.NewOpts[Key, uint32](hopmap.WithSize(0)) })
+	require.Panics(t, func() { hopmap.NewOpts[Key, uint32](hopmap.WithBucketSize(33)) })
+	require.Panics(t, func() { hopmap.NewOpts[Key, uint32](hopmap.WithMaxLoad(1.5)) })
+}
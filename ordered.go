@@ -0,0 +1,145 @@
+package hopmap
+
+import (
+	"cmp"
+	"slices"
+)
+
+// OrderedKey is satisfied by hashable keys whose underlying type is ordered.
+type OrderedKey[K any] interface {
+	Hashable[K]
+	cmp.Ordered
+}
+
+// MinKey returns the smallest key of the map.
+func MinKey[K OrderedKey[K], V any](m *Map[K, V]) (K, bool) {
+	return extremeKey(m, func(a, b K) bool { return a < b })
+}
+
+// MaxKey returns the largest key of the map.
+func MaxKey[K OrderedKey[K], V any](m *Map[K, V]) (K, bool) {
+	return extremeKey(m, func(a, b K) bool { return a > b })
+}
+
+func extremeKey[K OrderedKey[K], V any](m *Map[K, V], better func(a, b K) bool) (K, bool) {
+	var res K
+	found := false
+	m.RangeKeys(func(k K) bool {
+		if !found || better(k, res) {
+			res, found = k, true
+		}
+		return true
+	})
+	return res, found
+}
+
+// MaxValue returns the entry holding the largest value according to less.
+func MaxValue[K Hashable[K], V any](m *Map[K, V], less func(a, b V) bool) (K, V, bool) {
+	var (
+		key   K
+		value V
+	)
+	found := false
+	m.Range(func(k K, v V) bool {
+		if !found || less(value, v) {
+			key, value, found = k, v, true
+		}
+		return true
+	})
+	return key, value, found
+}
+
+// SortedEntries returns the keys of the map in ascending order, along with their values.
+// It materializes and sorts all the entries, so it runs in O(Len log Len) time.
+func SortedEntries[K OrderedKey[K], V any](m *Map[K, V]) ([]K, []V) {
+	pairs := m.pairs()
+	slices.SortFunc(pairs, func(a, b Pair[K, V]) int { return cmp.Compare(a.Key, b.Key) })
+
+	keys, values := make([]K, len(pairs)), make([]V, len(pairs))
+	for i, p := range pairs {
+		keys[i], values[i] = p.Key, p.Value
+	}
+	return keys, values
+}
+
+// SortedBy returns all the entries of the map sorted according to less, which may compare both keys and values.
+// Like SortedEntries, it runs in O(Len log Len) time. The order of entries which compare equal is unspecified.
+func (m *Map[K, V]) SortedBy(less func(a, b Pair[K, V]) bool) []Pair[K, V] {
+	pairs := m.pairs()
+	slices.SortFunc(pairs, func(a, b Pair[K, V]) int {
+		switch {
+		case less(a, b):
+			return -1
+		case less(b, a):
+			return 1
+		}
+		return 0
+	})
+	return pairs
+}
+
+func (m *Map[K, V]) pairs() []Pair[K, V] {
+	pairs := make([]Pair[K, V], 0, m.Len())
+	m.Range(func(k K, v V) bool {
+		pairs = append(pairs, Pair[K, V]{k, v})
+		return true
+	})
+	return pairs
+}
+
+// TopK returns up to k entries holding the largest values according to less, from the largest down.
+// It keeps a bounded min-heap during a single scan, so it runs in O(Len log k) time.
+func TopK[K Hashable[K], V any](m *Map[K, V], k int, less func(a, b V) bool) []Pair[K, V] {
+	if k <= 0 {
+		return nil
+	}
+
+	// heap is a min-heap of the largest values seen so far
+	heap := make([]Pair[K, V], 0, min(k, m.Len()))
+	m.Range(func(key K, v V) bool {
+		switch {
+		case len(heap) < k:
+			heap = append(heap, Pair[K, V]{key, v})
+			siftUp(heap, len(heap)-1, less)
+		case less(heap[0].Value, v):
+			heap[0] = Pair[K, V]{key, v}
+			siftDown(heap, 0, less)
+		}
+		return true
+	})
+
+	// popping the minimum to the end of the slice leaves it sorted from the largest value
+	for n := len(heap) - 1; n > 0; n-- {
+		heap[0], heap[n] = heap[n], heap[0]
+		siftDown(heap[:n], 0, less)
+	}
+	return heap
+}
+
+func siftUp[K, V any](heap []Pair[K, V], i int, less func(a, b V) bool) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !less(heap[i].Value, heap[parent].Value) {
+			return
+		}
+		heap[i], heap[parent] = heap[parent], heap[i]
+		i = parent
+	}
+}
+
+func siftDown[K, V any](heap []Pair[K, V], i int, less func(a, b V) bool) {
+	for {
+		smallest := i
+		if l := 2*i + 1; l < len(heap) && less(heap[l].Value, heap[smallest].Value) {
+			smallest = l
+		}
+		if r := 2*i + 2; r < len(heap) && less(heap[r].Value, heap[smallest].Value) {
+			smallest = r
+		}
+		if smallest == i {
+			return
+		}
+		heap[i], heap[smallest] = heap[smallest], heap[i]
+		i = smallest
+	}
+}
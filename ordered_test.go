@@ -0,0 +1,104 @@
+package hopmap_test
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinMax(t *testing.T) {
+	m := hopmap.New[Key, int](hopmap.Config{Size: 64, BucketSize: 8})
+
+	_, ok := hopmap.MinKey(m)
+	require.False(t, ok)
+
+	for _, k := range []Key{17, 3, 42, 8, 25} {
+		m.Put(k, -int(k)%10)
+	}
+
+	min, ok := hopmap.MinKey(m)
+	require.True(t, ok)
+	require.Equal(t, Key(3), min)
+
+	max, ok := hopmap.MaxKey(m)
+	require.True(t, ok)
+	require.Equal(t, Key(42), max)
+
+	k, v, ok := hopmap.MaxValue(m, func(a, b int) bool { return a < b })
+	require.True(t, ok)
+	require.Equal(t, Key(42), k)
+	require.Equal(t, -2, v)
+}
+
+func TestSortedEntries(t *testing.T) {
+	m := hopmap.New[Key, int](hopmap.Config{Size: 1 << 10, BucketSize: 32})
+
+	keys, values := hopmap.SortedEntries(m)
+	require.Empty(t, keys)
+	require.Empty(t, values)
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		k := Key(r.Intn(1 << 16))
+		m.Put(k, -int(k))
+	}
+
+	keys, values = hopmap.SortedEntries(m)
+	require.Len(t, keys, m.Len())
+	require.Len(t, values, m.Len())
+	require.True(t, slices.IsSorted(keys))
+	for i, k := range keys {
+		require.Equal(t, -int(k), values[i])
+	}
+}
+
+func TestSortedBy(t *testing.T) {
+	m := hopmap.New[Key, int](hopmap.Config{Size: 64, BucketSize: 8})
+	byValueDesc := func(a, b hopmap.Pair[Key, int]) bool {
+		if a.Value != b.Value {
+			return a.Value > b.Value
+		}
+		return a.Key < b.Key
+	}
+	require.Empty(t, m.SortedBy(byValueDesc))
+
+	for i, v := range []int{5, 42, 7, 19, 5, 27, 11} {
+		m.Put(Key(i), v)
+	}
+
+	require.Equal(t, []hopmap.Pair[Key, int]{
+		{Key: 1, Value: 42},
+		{Key: 5, Value: 27},
+		{Key: 3, Value: 19},
+		{Key: 6, Value: 11},
+		{Key: 2, Value: 7},
+		{Key: 0, Value: 5},
+		{Key: 4, Value: 5},
+	}, m.SortedBy(byValueDesc))
+}
+
+func TestTopK(t *testing.T) {
+	m := hopmap.New[Key, int](hopmap.Config{Size: 64, BucketSize: 8})
+	less := func(a, b int) bool { return a < b }
+
+	require.Empty(t, hopmap.TopK(m, 3, less))
+
+	for i, v := range []int{5, 42, 7, 19, 3, 27, 11} {
+		m.Put(Key(i), v)
+	}
+
+	require.Equal(t, []hopmap.Pair[Key, int]{
+		{Key: 1, Value: 42},
+		{Key: 5, Value: 27},
+		{Key: 3, Value: 19},
+	}, hopmap.TopK(m, 3, less))
+
+	all := hopmap.TopK(m, 100, less)
+	require.Len(t, all, m.Len())
+	require.True(t, slices.IsSortedFunc(all, func(a, b hopmap.Pair[Key, int]) int { return b.Value - a.Value }))
+
+	require.Empty(t, hopmap.TopK(m, 0, less))
+}
@@ -0,0 +1,43 @@
+package hopmap
+
+func (m *Map[K, V]) findOverflow(key K) int {
+	for i, e := range m.overflow {
+		if e.key.Equals(key) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *Map[K, V]) appendOverflow(e *entry[K, V]) {
+	if len(m.overflow) == cap(m.overflow) {
+		growth := m.config.OverflowGrowth
+		if growth <= 1 {
+			growth = 2
+		}
+
+		c := max(m.config.OverflowCapacity, int(float64(cap(m.overflow))*growth), cap(m.overflow)+1)
+		overflow := make([]*entry[K, V], len(m.overflow), c)
+		copy(overflow, m.overflow)
+		m.overflow = overflow
+	}
+	m.overflow = append(m.overflow, e)
+	m.n++
+}
+
+// deleteOverflow removes the i-th entry of the overflow set, replacing it with the last one.
+func (m *Map[K, V]) deleteOverflow(i int) V {
+	m.countDelete()
+
+	e := m.overflow[i]
+	value := e.value
+	m.unpinDeleted(e.key)
+	m.freeEntry(e)
+
+	last := len(m.overflow) - 1
+	m.overflow[i] = m.overflow[last]
+	m.overflow[last] = nil
+	m.overflow = m.overflow[:last]
+	m.n--
+	return value
+}
@@ -0,0 +1,168 @@
+package hopmap_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverflow(t *testing.T) {
+	m := hopmap.New[constKey, int](hopmap.Config{
+		Size:             16,
+		BucketSize:       4,
+		AllowOverflow:    true,
+		OverflowCapacity: 2,
+		OverflowGrowth:   2,
+	})
+
+	caps := []int{}
+	for i := 0; i < 20; i++ {
+		require.True(t, m.Put(constKey(i), i))
+
+		if s := m.Stats(); len(caps) == 0 || caps[len(caps)-1] != s.OverflowCap {
+			caps = append(caps, s.OverflowCap)
+		}
+	}
+	require.Equal(t, []int{0, 2, 4, 8, 16}, caps)
+	require.Equal(t, 16, m.Stats().Overflow)
+	require.Equal(t, 20, m.Len())
+	require.NoError(t, m.Validate())
+
+	for i := 0; i < 20; i++ {
+		v, ok := m.Get(constKey(i))
+		require.True(t, ok)
+		require.Equal(t, i, v)
+	}
+
+	require.True(t, m.Put(constKey(10), 100))
+	v, _ := m.Get(10)
+	require.Equal(t, 100, v)
+
+	v, ok := m.Delete(constKey(10))
+	require.True(t, ok)
+	require.Equal(t, 100, v)
+	require.Equal(t, 15, m.Stats().Overflow)
+
+	count := 0
+	m.Range(func(k constKey, v int) bool {
+		count++
+		return true
+	})
+	require.Equal(t, 19, count)
+	require.NoError(t, m.Validate())
+}
+
+func TestOverflowDisabled(t *testing.T) {
+	m := hopmap.New[constKey, int](hopmap.Config{Size: 16, BucketSize: 4})
+	for i := 0; i < 4; i++ {
+		require.True(t, m.Put(constKey(i), i))
+	}
+	require.ErrorIs(t, m.TryPut(4, 4), hopmap.ErrTableFull)
+	require.Zero(t, m.Stats().Overflow)
+}
+
+func TestOverflowLen(t *testing.T) {
+	m := hopmap.New[constKey, int](hopmap.Config{
+		Size:          16,
+		BucketSize:    4,
+		AllowOverflow: true,
+	})
+
+	for i := 0; i < 10; i++ {
+		require.True(t, m.Put(constKey(i), i))
+		require.Equal(t, i+1, m.Len())
+	}
+	require.Equal(t, 6, m.Stats().Overflow)
+
+	// overwrites must not be counted
+	require.True(t, m.Put(constKey(0), 0))
+	require.True(t, m.Put(constKey(9), 9))
+	require.Equal(t, 10, m.Len())
+
+	// rehashing moves entries between the table and the overflow set
+	require.NoError(t, m.Resize(64))
+	require.Equal(t, 10, m.Len())
+	require.NoError(t, m.Validate())
+
+	for _, i := range []int{0, 9, 5} {
+		_, ok := m.Delete(constKey(i))
+		require.True(t, ok)
+	}
+	_, ok := m.Delete(constKey(0))
+	require.False(t, ok)
+	require.Equal(t, 7, m.Len())
+	require.NoError(t, m.Validate())
+
+	m.Clear()
+	require.Zero(t, m.Len())
+	require.Zero(t, m.Stats().Overflow)
+	require.NoError(t, m.Validate())
+
+	require.True(t, m.Put(constKey(1), 1))
+	require.Equal(t, 1, m.Len())
+}
+
+func TestDrain(t *testing.T) {
+	m := hopmap.New[constKey, int](hopmap.Config{
+		Size:          64,
+		BucketSize:    8,
+		AllowOverflow: true,
+		UseTombstones: true,
+	})
+	for i := 0; i < 20; i++ {
+		require.True(t, m.Put(constKey(i), i))
+	}
+	require.NotZero(t, m.Stats().Overflow)
+
+	seen := make(map[constKey]int)
+	for p := range m.Drain(context.Background()) {
+		require.Equal(t, int(p.Key), p.Value)
+		seen[p.Key]++
+	}
+	require.Len(t, seen, 20)
+	for _, n := range seen {
+		require.Equal(t, 1, n)
+	}
+
+	require.Zero(t, m.Len())
+	require.Zero(t, m.Stats().Overflow)
+	require.NoError(t, m.Validate())
+
+	_, ok := <-m.Drain(context.Background())
+	require.False(t, ok)
+}
+
+func TestDrainCancel(t *testing.T) {
+	m := hopmap.New[constKey, int](hopmap.Config{
+		Size:          64,
+		BucketSize:    8,
+		AllowOverflow: true,
+	})
+	for i := 0; i < 20; i++ {
+		require.True(t, m.Put(constKey(i), i))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := m.Drain(ctx)
+
+	received := make(map[constKey]bool)
+	for i := 0; i < 5; i++ {
+		p := <-ch
+		received[p.Key] = true
+	}
+	cancel()
+	for p := range ch {
+		// a send may race with the cancellation
+		received[p.Key] = true
+	}
+
+	// only the received entries are removed
+	require.Equal(t, 20-len(received), m.Len())
+	for i := 0; i < 20; i++ {
+		_, ok := m.Get(constKey(i))
+		require.Equal(t, !received[constKey(i)], ok)
+	}
+	require.NoError(t, m.Validate())
+}
@@ -0,0 +1,45 @@
+package hopmap
+
+// Pin marks key as pinned, so that evicting variants such as LRUMap never evict it.
+// It returns false if key is not in the map, or if the pin cannot be stored. Deleting a key unpins it.
+func (m *Map[K, V]) Pin(key K) bool {
+	if m.lookup(m.hashKey(key), key) == nil {
+		return false
+	}
+
+	if m.pins == nil {
+		m.pins = New[K, struct{}](Config{
+			Size:          8,
+			BucketSize:    8,
+			AutoResize:    true,
+			MaxLoad:       0.75,
+			Seed:          m.config.Seed,
+			HashFinalizer: m.config.HashFinalizer,
+		})
+	}
+	return m.pins.Put(key, struct{}{})
+}
+
+// Unpin removes the pin of key, reporting whether it was pinned.
+func (m *Map[K, V]) Unpin(key K) bool {
+	if m.pins == nil {
+		return false
+	}
+	_, ok := m.pins.Delete(key)
+	return ok
+}
+
+func (m *Map[K, V]) IsPinned(key K) bool {
+	if m.pins == nil || m.pins.Len() == 0 {
+		return false
+	}
+	_, ok := m.pins.Get(key)
+	return ok
+}
+
+// unpinDeleted drops the pin of a key being deleted, if any.
+func (m *Map[K, V]) unpinDeleted(key K) {
+	if m.pins != nil && m.pins.Len() > 0 {
+		m.pins.Delete(key)
+	}
+}
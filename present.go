@@ -0,0 +1,55 @@
+package hopmap
+
+import "math/bits"
+
+// The present bitmap holds one bit per slot, set when the slot is occupied (by an entry or a tombstone).
+// It allows looking for empty slots and scanning the table a word at a time,
+// without loading the entries themselves.
+
+func presentWords(size int) int {
+	return (size + 63) / 64
+}
+
+func (m *Map[_, _]) isPresent(j int) bool {
+	return m.present[j>>6]&(1<<(j&63)) != 0
+}
+
+func (m *Map[_, _]) markPresent(j int) {
+	m.present[j>>6] |= 1 << (j & 63)
+}
+
+func (m *Map[_, _]) markEmpty(j int) {
+	m.present[j>>6] &^= 1 << (j & 63)
+}
+
+// nextEmpty returns the first empty slot in [from, end), without wrapping around, or -1.
+func (m *Map[_, _]) nextEmpty(from, end int) int {
+	for w := from >> 6; w<<6 < end; w++ {
+		free := ^m.present[w]
+		if w == from>>6 {
+			free &= ^uint64(0) << (from & 63)
+		}
+
+		if free != 0 {
+			// bits past the last slot are never set, so they may come up here
+			if j := w<<6 + bits.TrailingZeros64(free); j < end {
+				return j
+			}
+			return -1
+		}
+	}
+	return -1
+}
+
+// forEachPresent calls fn with the index of each occupied slot, stopping as soon as fn returns false.
+func (m *Map[_, _]) forEachPresent(fn func(j int) bool) {
+	for w, word := range m.present {
+		for word != 0 {
+			b := bits.TrailingZeros64(word)
+			if !fn(w<<6 + b) {
+				return
+			}
+			word &= word - 1
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package hopmap
+
+// findEmptySlotBidirectional returns the empty slot nearest to startHash, probing alternately
+// forward and backward. A slot found before startHash is moved past it, falling back
+// to a forward search when this is not possible.
+func (m *Map[K, V]) findEmptySlotBidirectional(startHash uint32) int {
+	start, limit := int(startHash), min(m.size/2, m.config.probeLimit(m.size))
+	for d := 0; d <= limit; d++ {
+		if j := mod(start+d, m.size); m.entries[j] == nil {
+			return j
+		}
+
+		if j := mod(start-d, m.size); m.entries[j] == nil {
+			if k := m.pullEmptySlotPast(j, d); k >= 0 {
+				return k
+			}
+			return m.findEmptySlot(startHash)
+		}
+	}
+	return -1
+}
+
+// pullEmptySlotPast moves the empty slot j, lying behind bucket j+behind, past that bucket,
+// by repeatedly moving the farthest possible entry back into it, and returns its new position.
+// If the slot cannot be moved far enough, nothing is moved and -1 is returned.
+func (m *Map[K, V]) pullEmptySlotPast(j, behind int) int {
+	// a move only affects slots preceding the new empty slot,
+	// so the whole chain of moves can be planned in advance
+	var moves []pullMove
+	for k := j; behind > 0; {
+		mv, ok := m.farthestPullable(k)
+		if !ok {
+			return -1
+		}
+		moves = append(moves, mv)
+
+		behind -= mv.dist
+		k = mod(k+mv.dist, m.size)
+	}
+
+	for _, mv := range moves {
+		k := mod(j+mv.dist, m.size)
+		m.entries[j] = m.entries[k]
+		m.entries[k] = nil
+		m.markPresent(j)
+		m.markEmpty(k)
+		m.clearNeighbor(mv.bucket, mv.off)
+		m.setNeighbor(mv.bucket, mv.off-mv.dist)
+		m.countReshift(k, j)
+		j = k
+	}
+	return j
+}
+
+// pullMove describes moving the entry at offset off of bucket back by dist slots.
+type pullMove struct {
+	bucket, off, dist int
+}
+
+// farthestPullable returns the farthest entry following the empty slot j
+// whose neighborhood also covers j.
+func (m *Map[K, V]) farthestPullable(j int) (pullMove, bool) {
+	best := pullMove{bucket: -1}
+	for back := 0; back < m.config.BucketSize; back++ {
+		b := mod(j-back, m.size)
+		nb := m.neighbors[b]
+
+		// the last entry of b, if it follows j
+		for off := m.config.BucketSize - 1; off > back; off-- {
+			if nb&(1<<(31-off)) != 0 {
+				if off-back > best.dist {
+					best = pullMove{bucket: b, off: off, dist: off - back}
+				}
+				break
+			}
+		}
+	}
+	return best, best.bucket >= 0
+}
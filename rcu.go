@@ -0,0 +1,77 @@
+package hopmap
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// RCUMap is a Map safe for concurrent use, optimized for read-heavy workloads.
+// Readers access an immutable snapshot without locking, while each write clones the current snapshot,
+// modifies the clone and publishes it, so writes cost O(Size) and are serialized by a mutex.
+type RCUMap[K Hashable[K], V any] struct {
+	mu sync.Mutex // serializes writers
+	p  atomic.Pointer[Map[K, V]]
+}
+
+var _ GenericMap[hashableInt, int] = (*RCUMap[hashableInt, int])(nil)
+
+func NewRCU[K Hashable[K], V any](c Config) *RCUMap[K, V] {
+	r := &RCUMap[K, V]{}
+	r.p.Store(New[K, V](c))
+	return r
+}
+
+// Load returns the current snapshot, which reflects all the writes completed before the call.
+// It must not be modified.
+func (r *RCUMap[K, V]) Load() *Map[K, V] {
+	return r.p.Load()
+}
+
+// Update applies fn to a copy of the current snapshot, then publishes it.
+// Readers see either none or all of the changes made by fn.
+func (r *RCUMap[K, V]) Update(fn func(*Map[K, V])) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m := r.p.Load().Clone()
+	fn(m)
+	r.p.Store(m)
+}
+
+func (r *RCUMap[K, V]) Get(key K) (V, bool) {
+	return r.Load().Get(key)
+}
+
+func (r *RCUMap[K, V]) Put(key K, value V) bool {
+	var ok bool
+	r.Update(func(m *Map[K, V]) {
+		ok = m.Put(key, value)
+	})
+	return ok
+}
+
+func (r *RCUMap[K, V]) Delete(key K) (V, bool) {
+	// avoid cloning when there is nothing to delete
+	if m := r.Load(); m.lookup(m.hashKey(key), key) == nil {
+		return zeroValue[V](), false
+	}
+
+	var (
+		value V
+		ok    bool
+	)
+	r.Update(func(m *Map[K, V]) {
+		value, ok = m.Delete(key)
+	})
+	return value, ok
+}
+
+func (r *RCUMap[K, V]) Len() int {
+	return r.Load().Len()
+}
+
+// Range iterates over the current snapshot, so fn may modify the map,
+// but its changes are not visible to the iteration.
+func (r *RCUMap[K, V]) Range(fn func(K, V) bool) {
+	r.Load().Range(fn)
+}
@@ -0,0 +1,115 @@
+package hopmap
+
+import (
+	"errors"
+	"slices"
+)
+
+// ringPos is a position on a Ring, already well mixed, hence its own hash code.
+type ringPos uint32
+
+func (x ringPos) Equals(y ringPos) bool {
+	return x == y
+}
+
+func (x ringPos) HashCode() uint32 {
+	return uint32(x)
+}
+
+// Ring is a consistent hashing ring, routing keys to nodes: each node is placed at several
+// positions on the ring, its virtual nodes, and a key belongs to the first virtual node found
+// clockwise from its own position. Adding or removing a node only moves the keys of that node.
+type Ring[V Hashable[V]] struct {
+	owners    *Map[ringPos, V]
+	vnodes    *Map[V, int]
+	positions []uint32 // sorted positions of all the virtual nodes
+}
+
+// NewRing creates an empty ring, whose internal maps are configured by c.
+func NewRing[V Hashable[V]](c Config) *Ring[V] {
+	return &Ring[V]{
+		owners: New[ringPos, V](c),
+		vnodes: New[V, int](c),
+	}
+}
+
+// vnodePos returns the position of the i-th virtual node of node.
+func vnodePos[V Hashable[V]](node V, i int) ringPos {
+	return ringPos(fmix32(node.HashCode() ^ fmix32(uint32(i)+1)))
+}
+
+// Add places node on the ring with the given number of virtual nodes, reporting whether it was absent
+// and could be placed. Virtual nodes colliding with those of other nodes are skipped. If the ring
+// runs out of room, the virtual nodes placed so far are taken off again, leaving the ring unchanged.
+func (r *Ring[V]) Add(node V, vnodes int) bool {
+	if _, ok := r.vnodes.Get(node); ok || vnodes <= 0 {
+		return false
+	}
+
+	placed := make([]uint32, 0, vnodes)
+	for i := 0; i < vnodes; i++ {
+		pos := vnodePos(node, i)
+		err := r.owners.PutUnique(pos, node)
+		if errors.Is(err, ErrTableFull) {
+			r.unplace(placed)
+			return false
+		}
+		if err == nil {
+			placed = append(placed, uint32(pos))
+		}
+	}
+
+	if !r.vnodes.Put(node, vnodes) {
+		r.unplace(placed)
+		return false
+	}
+	r.positions = append(r.positions, placed...)
+	slices.Sort(r.positions)
+	return true
+}
+
+// unplace takes the given virtual node positions off the ring, undoing a partial Add.
+func (r *Ring[V]) unplace(positions []uint32) {
+	for _, pos := range positions {
+		r.owners.Delete(ringPos(pos))
+	}
+}
+
+// Remove takes node off the ring, reporting whether it was present.
+func (r *Ring[V]) Remove(node V) bool {
+	vnodes, ok := r.vnodes.Delete(node)
+	if !ok {
+		return false
+	}
+
+	for i := 0; i < vnodes; i++ {
+		// positions taken by other nodes were skipped by Add
+		pos := vnodePos(node, i)
+		if owner, ok := r.owners.Get(pos); ok && owner.Equals(node) {
+			r.owners.Delete(pos)
+		}
+	}
+	r.positions = slices.DeleteFunc(r.positions, func(p uint32) bool {
+		_, ok := r.owners.Get(ringPos(p))
+		return !ok
+	})
+	return true
+}
+
+// Locate returns the node owning the given key position, or false if the ring is empty.
+func (r *Ring[V]) Locate(key uint32) (V, bool) {
+	if len(r.positions) == 0 {
+		return zeroValue[V](), false
+	}
+
+	i, _ := slices.BinarySearch(r.positions, key)
+	if i == len(r.positions) {
+		i = 0
+	}
+	return r.owners.Get(ringPos(r.positions[i]))
+}
+
+// Len returns the number of nodes on the ring.
+func (r *Ring[V]) Len() int {
+	return r.vnodes.Len()
+}
@@ -0,0 +1,105 @@
+package hopmap_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRing(t *testing.T) {
+	r := hopmap.NewRing[Key](hopmap.Config{Size: 1 << 10, BucketSize: 32, AutoResize: true, MaxLoad: 0.75})
+	_, ok := r.Locate(0)
+	require.False(t, ok)
+
+	const nodes = 10
+	for n := 0; n < nodes; n++ {
+		require.True(t, r.Add(Key(n), 200))
+	}
+	require.False(t, r.Add(0, 200))
+	require.Equal(t, nodes, r.Len())
+
+	rnd := rand.New(rand.NewSource(1))
+	keys := make([]uint32, 100000)
+	for i := range keys {
+		keys[i] = rnd.Uint32()
+	}
+
+	locate := func() map[uint32]Key {
+		owners := make(map[uint32]Key, len(keys))
+		for _, k := range keys {
+			node, ok := r.Locate(k)
+			require.True(t, ok)
+			owners[k] = node
+		}
+		return owners
+	}
+
+	// keys spread evenly across the nodes
+	before := locate()
+	counts := make(map[Key]int)
+	for _, node := range before {
+		counts[node]++
+	}
+	require.Len(t, counts, nodes)
+	for _, c := range counts {
+		require.InDelta(t, len(keys)/nodes, c, 0.25*float64(len(keys)/nodes))
+	}
+
+	// a new node only takes keys from the others
+	require.True(t, r.Add(nodes, 200))
+	after := locate()
+	moved := 0
+	for k, node := range after {
+		if node != before[k] {
+			require.Equal(t, Key(nodes), node)
+			moved++
+		}
+	}
+	require.InDelta(t, len(keys)/(nodes+1), moved, 0.25*float64(len(keys)/(nodes+1)))
+
+	// removing a node only moves its own keys
+	require.True(t, r.Remove(3))
+	require.False(t, r.Remove(3))
+	for k, node := range locate() {
+		if after[k] != 3 {
+			require.Equal(t, after[k], node)
+		} else {
+			require.NotEqual(t, Key(3), node)
+		}
+	}
+
+	for n := 0; n <= nodes; n++ {
+		r.Remove(Key(n))
+	}
+	require.Zero(t, r.Len())
+	_, ok = r.Locate(0)
+	require.False(t, ok)
+}
+
+func TestRingFull(t *testing.T) {
+	r := hopmap.NewRing[Key](hopmap.Config{Size: 64, BucketSize: 8})
+	require.True(t, r.Add(1, 16))
+
+	owners := make(map[uint32]Key)
+	for k := uint32(0); k < 1<<16; k += 97 {
+		node, ok := r.Locate(k)
+		require.True(t, ok)
+		owners[k] = node
+	}
+
+	// the vnodes of the new node cannot all fit, so none of them is kept
+	require.False(t, r.Add(2, 100))
+	require.Equal(t, 1, r.Len())
+	for k, owner := range owners {
+		node, ok := r.Locate(k)
+		require.True(t, ok)
+		require.Equal(t, owner, node)
+	}
+	require.False(t, r.Remove(2))
+
+	// the room left is still available to other nodes
+	require.True(t, r.Add(3, 16))
+	require.Equal(t, 2, r.Len())
+}
@@ -0,0 +1,91 @@
+package hopmap
+
+import "sync"
+
+type shard[K Hashable[K], V any] struct {
+	mu sync.RWMutex
+	m  *Map[K, V]
+}
+
+// ShardedMap is safe for concurrent use, spreading keys across independently locked Maps
+// so that operations on different shards do not contend.
+type ShardedMap[K Hashable[K], V any] struct {
+	shards []shard[K, V]
+}
+
+var _ GenericMap[hashableInt, int] = (*ShardedMap[hashableInt, int])(nil)
+
+// NewSharded creates a map made of n shards, each configured by c.
+func NewSharded[K Hashable[K], V any](n int, c Config) *ShardedMap[K, V] {
+	s := &ShardedMap[K, V]{shards: make([]shard[K, V], n)}
+	for i := range s.shards {
+		s.shards[i].m = New[K, V](c)
+	}
+	return s
+}
+
+// shardOf mixes the hash before reducing it, so that the keys of a shard
+// do not all share the same low bits, which the shard uses to pick home buckets.
+func (s *ShardedMap[K, V]) shardOf(key K) *shard[K, V] {
+	return &s.shards[fmix32(key.HashCode())%uint32(len(s.shards))]
+}
+
+func (s *ShardedMap[K, V]) Get(key K) (V, bool) {
+	sh := s.shardOf(key)
+
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	return sh.m.Get(key)
+}
+
+func (s *ShardedMap[K, V]) Put(key K, value V) bool {
+	sh := s.shardOf(key)
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	return sh.m.Put(key, value)
+}
+
+func (s *ShardedMap[K, V]) Delete(key K) (V, bool) {
+	sh := s.shardOf(key)
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	return sh.m.Delete(key)
+}
+
+func (s *ShardedMap[K, V]) Len() int {
+	n := 0
+	for i := range s.shards {
+		sh := &s.shards[i]
+
+		sh.mu.RLock()
+		n += sh.m.Len()
+		sh.mu.RUnlock()
+	}
+	return n
+}
+
+// Range visits one shard at a time, holding its read lock, so fn must not modify the map.
+func (s *ShardedMap[K, V]) Range(fn func(K, V) bool) {
+	for i := range s.shards {
+		if !s.shards[i].rangeLocked(fn) {
+			return
+		}
+	}
+}
+
+func (sh *shard[K, V]) rangeLocked(fn func(K, V) bool) bool {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	completed := true
+	sh.m.Range(func(k K, v V) bool {
+		completed = fn(k, v)
+		return completed
+	})
+	return completed
+}
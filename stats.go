@@ -0,0 +1,73 @@
+package hopmap
+
+import "sync/atomic"
+
+// Stats holds operation counters, collected only when Config.Stats is set,
+// along with the current length and capacity of the overflow set, which are always reported.
+type Stats struct {
+	Gets, Hits     uint64
+	Puts, Failures uint64
+	Deletes        uint64
+	Reshifts       uint64
+	Resizes        uint64
+
+	Overflow, OverflowCap int
+}
+
+// Stats returns a copy of the counters. Gets and Hits are loaded atomically,
+// so it can run concurrently with lookups.
+func (m *Map[_, _]) Stats() Stats {
+	return Stats{
+		Gets:        atomic.LoadUint64(&m.stats.Gets),
+		Hits:        atomic.LoadUint64(&m.stats.Hits),
+		Puts:        m.stats.Puts,
+		Failures:    m.stats.Failures,
+		Deletes:     m.stats.Deletes,
+		Reshifts:    m.stats.Reshifts,
+		Resizes:     m.stats.Resizes,
+		Overflow:    len(m.overflow),
+		OverflowCap: cap(m.overflow),
+	}
+}
+
+// countGet updates counters atomically, since lookups may run concurrently under a read lock.
+func (m *Map[_, _]) countGet(hit bool) {
+	if m.config.Stats {
+		atomic.AddUint64(&m.stats.Gets, 1)
+		if hit {
+			atomic.AddUint64(&m.stats.Hits, 1)
+		}
+	}
+}
+
+func (m *Map[_, _]) countPut(ok bool) {
+	if m.config.Stats {
+		if ok {
+			m.stats.Puts++
+		} else {
+			m.stats.Failures++
+		}
+	}
+}
+
+func (m *Map[_, _]) countDelete() {
+	if m.config.Stats {
+		m.stats.Deletes++
+	}
+}
+
+// countReshift also reports to Config.OnReshift the move of an entry from one slot to another.
+func (m *Map[_, _]) countReshift(from, to int) {
+	if m.config.Stats {
+		m.stats.Reshifts++
+	}
+	if m.config.OnReshift != nil {
+		m.config.OnReshift(from, to)
+	}
+}
+
+func (m *Map[_, _]) countResize() {
+	if m.config.Stats {
+		m.stats.Resizes++
+	}
+}
@@ -0,0 +1,80 @@
+package hopmap_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoResize(t *testing.T) {
+	m := hopmap.New[Key, uint32](hopmap.Config{Size: 16, BucketSize: 8, AutoResize: true, MaxLoad: 0.5, Stats: true})
+	for i := 0; i < 100; i++ {
+		require.True(t, m.Put(Key(i), uint32(i)))
+	}
+	require.LessOrEqual(t, m.Load(), 0.5)
+
+	for i := 0; i < 100; i++ {
+		v, ok := m.Get(Key(i))
+		require.True(t, ok)
+		require.Equal(t, uint32(i), v)
+	}
+	m.Get(100)
+	m.Delete(0)
+
+	s := m.Stats()
+	require.Equal(t, uint64(100), s.Puts)
+	require.Equal(t, uint64(101), s.Gets)
+	require.Equal(t, uint64(100), s.Hits)
+	require.Equal(t, uint64(1), s.Deletes)
+	require.NotZero(t, s.Resizes)
+
+	fixed := hopmap.New[Key, uint32](hopmap.Config{Size: 16, BucketSize: 8})
+	for i := 0; i < 16; i++ {
+		require.True(t, fixed.Put(Key(i), uint32(i)))
+	}
+	require.False(t, fixed.Put(16, 16))
+	require.Equal(t, 16, fixed.Size())
+	require.Zero(t, fixed.Stats())
+}
+
+func TestAutoResizeCollisions(t *testing.T) {
+	// keys sharing a hash code never spread out, whatever the size of the table
+	m := hopmap.New[constKey, int](hopmap.Config{Size: 16, BucketSize: 8, AutoResize: true})
+	for i := 0; i < 8; i++ {
+		require.True(t, m.Put(constKey(i), i))
+	}
+	for i := 8; i < 16; i++ {
+		require.ErrorIs(t, m.TryPut(constKey(i), i), hopmap.ErrTableFull)
+	}
+	require.Equal(t, 16, m.Size())
+
+	overflowing := hopmap.New[constKey, int](hopmap.Config{Size: 16, BucketSize: 8, AutoResize: true, AllowOverflow: true})
+	for i := 0; i < 16; i++ {
+		require.True(t, overflowing.Put(constKey(i), i))
+	}
+	require.Equal(t, 16, overflowing.Size())
+	require.Equal(t, 16, overflowing.Len())
+
+	// keys sharing a home bucket at small sizes are spread out by as many grows as needed
+	spread := hopmap.New[Key, int](hopmap.Config{Size: 16, BucketSize: 8, AutoResize: true})
+	for i := 0; i < 9; i++ {
+		require.True(t, spread.Put(Key(i*64), i))
+	}
+	require.Equal(t, 128, spread.Size())
+	require.NoError(t, spread.Validate())
+}
+
+func TestAutoResizeLimit(t *testing.T) {
+	_, err := hopmap.NewE[Key, int](hopmap.Config{Size: 16, BucketSize: 4, AutoResize: true})
+	require.ErrorIs(t, err, hopmap.ErrInvalidConfig)
+
+	// tiny neighborhoods overflow on random keys at any size, which must not exhaust memory
+	m := hopmap.New[Key, int](hopmap.Config{Size: 16, BucketSize: 1, AutoResize: true})
+	r := rand.New(rand.NewSource(1))
+	for m.Put(Key(r.Uint32()), 0) {
+	}
+	require.LessOrEqual(t, m.Size(), max(8*(m.Len()+1), 1<<10))
+	require.NoError(t, m.Validate())
+}
@@ -0,0 +1,60 @@
+package hopmap
+
+import "sync"
+
+// SyncMap is a Map safe for concurrent use, guarded by a single RWMutex.
+type SyncMap[K Hashable[K], V any] struct {
+	mu sync.RWMutex
+	m  *Map[K, V]
+}
+
+var _ GenericMap[hashableInt, int] = (*SyncMap[hashableInt, int])(nil)
+
+func NewSync[K Hashable[K], V any](c Config) *SyncMap[K, V] {
+	return &SyncMap[K, V]{m: New[K, V](c)}
+}
+
+func (s *SyncMap[K, V]) Get(key K) (V, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.Get(key)
+}
+
+func (s *SyncMap[K, V]) Put(key K, value V) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.m.Put(key, value)
+}
+
+// Delete holds the write lock until it returns, since deleting may shrink the map when MinLoad is set,
+// rehashing all the entries.
+func (s *SyncMap[K, V]) Delete(key K) (V, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.m.Delete(key)
+}
+
+func (s *SyncMap[K, V]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.Len()
+}
+
+func (s *SyncMap[K, V]) Size() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.m.Size()
+}
+
+// Range holds the read lock for the whole iteration, so fn must not modify the map.
+func (s *SyncMap[K, V]) Range(fn func(K, V) bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	s.m.Range(fn)
+}
@@ -0,0 +1,51 @@
+package hopmap
+
+// reclaimTombstone stores e into a tombstone of the bucket hash, if there is one.
+func (m *Map[K, V]) reclaimTombstone(hash uint32, e *entry[K, V]) bool {
+	if m.tombs == 0 {
+		return false
+	}
+
+	for nb, off := m.neighbors[hash], 0; nb != 0; nb, off = nb<<1, off+1 {
+		if nb&(1<<31) == 0 {
+			continue
+		}
+
+		if j := mod(int(hash)+off, m.size); m.entries[j] == m.tomb {
+			m.entries[j] = e
+			m.tombs--
+			m.n++
+			return true
+		}
+	}
+	return false
+}
+
+// purgeTombstonesIfNeeded purges tombstones once they outnumber the empty slots,
+// which would otherwise force insertions into other buckets to probe further and reshift more.
+func (m *Map[K, V]) purgeTombstonesIfNeeded() {
+	empty := m.size - (m.n - len(m.overflow)) - m.tombs
+	if m.tombs > empty {
+		m.purgeTombstones()
+	}
+}
+
+// purgeTombstones empties the slots held by tombstones, clearing their neighbor bits.
+func (m *Map[K, V]) purgeTombstones() {
+	for j := 0; m.tombs > 0 && j < m.size; j++ {
+		if m.entries[j] != m.tomb {
+			continue
+		}
+
+		// exactly one bucket within reach refers to the slot
+		for off := 0; off < m.config.BucketSize; off++ {
+			if b := mod(j-off, m.size); m.neighbors[b]&(1<<(31-off)) != 0 {
+				m.clearNeighbor(b, off)
+				break
+			}
+		}
+		m.entries[j] = nil
+		m.markEmpty(j)
+		m.tombs--
+	}
+}
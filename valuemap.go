@@ -0,0 +1,54 @@
+package hopmap
+
+// ValueMap is a hopscotch map storing entries by value rather than through pointers,
+// which saves an indirection, and a likely cache miss, on every lookup.
+// It suits small keys and values, since entries are copied when moved.
+// Only the Size, BucketSize, AutoResize, MaxLoad, Seed, HashFinalizer and MaxProbeBuckets fields of Config are used.
+type ValueMap[K Hashable[K], V any] struct {
+	inlineTable[K, V, valueSlots[K, V]]
+}
+
+// valueEntry is an entry stored inline, without the bookkeeping fields of entry.
+type valueEntry[K, V any] struct {
+	key   K
+	value V
+}
+
+// valueSlots stores each key next to its value.
+type valueSlots[K, V any] []valueEntry[K, V]
+
+var _ GenericMap[hashableInt, int] = (*ValueMap[hashableInt, int])(nil)
+
+// NewValue creates a ValueMap from the given config, rounding Size as New does.
+// It panics if the config is invalid.
+func NewValue[K Hashable[K], V any](c Config) *ValueMap[K, V] {
+	return &ValueMap[K, V]{newInlineTable[K, V, valueSlots[K, V]](c)}
+}
+
+func (valueSlots[K, V]) alloc(size int) valueSlots[K, V] {
+	return make(valueSlots[K, V], size)
+}
+
+func (s valueSlots[K, V]) key(j int) K {
+	return s[j].key
+}
+
+func (s valueSlots[K, V]) value(j int) V {
+	return s[j].value
+}
+
+func (s valueSlots[K, V]) set(j int, key K, value V) {
+	s[j] = valueEntry[K, V]{key, value}
+}
+
+func (s valueSlots[K, V]) setValue(j int, value V) {
+	s[j].value = value
+}
+
+func (s valueSlots[K, V]) move(from, to int) {
+	s[to], s[from] = s[from], valueEntry[K, V]{}
+}
+
+func (s valueSlots[K, V]) clear(j int) {
+	s[j] = valueEntry[K, V]{}
+}
@@ -0,0 +1,38 @@
+package hopmap_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/ostafen/hopmap"
+)
+
+func BenchmarkGetValueMap(b *testing.B) {
+	const size = 1 << 22
+	c := hopmap.Config{Size: size, BucketSize: 32}
+
+	r := rand.New(rand.NewSource(1))
+	keys := make([]Key, size/2)
+	for i := range keys {
+		keys[i] = Key(r.Uint32())
+	}
+	lookups := make([]Key, 1<<16)
+	for i := range lookups {
+		lookups[i] = keys[r.Intn(len(keys))]
+	}
+
+	for name, m := range map[string]hopmap.GenericMap[Key, uint32]{
+		"Map":      hopmap.New[Key, uint32](c),
+		"ValueMap": hopmap.NewValue[Key, uint32](c),
+	} {
+		for i, k := range keys {
+			m.Put(k, uint32(i))
+		}
+
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				m.Get(lookups[i&(len(lookups)-1)])
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package hopmap
+
+// versionRing holds the last values of a key in a ring buffer, overwriting the oldest one when full.
+type versionRing[V any] struct {
+	values []V
+	head   int // index of the latest value
+	n      int
+}
+
+func (r *versionRing[V]) push(v V) {
+	r.head = (r.head + 1) % len(r.values)
+	r.values[r.head] = v
+	r.n = min(r.n+1, len(r.values))
+}
+
+// at returns the value pushed back values before the latest one.
+func (r *versionRing[V]) at(back int) (V, bool) {
+	if back < 0 || back >= r.n {
+		return zeroValue[V](), false
+	}
+	return r.values[mod(r.head-back, len(r.values))], true
+}
+
+// VersionedMap keeps the last few values put for each key, so that older versions
+// can still be read, for example to serve snapshot reads of an in-memory cache.
+// Get, Delete and Range only deal with the latest version.
+type VersionedMap[K Hashable[K], V any] struct {
+	m        *Map[K, versionRing[V]]
+	versions int
+}
+
+var _ GenericMap[hashableInt, int] = (*VersionedMap[hashableInt, int])(nil)
+
+// NewVersioned creates a VersionedMap keeping up to versions values per key, at least one.
+func NewVersioned[K Hashable[K], V any](versions int, c Config) *VersionedMap[K, V] {
+	return &VersionedMap[K, V]{
+		m:        New[K, versionRing[V]](c),
+		versions: max(versions, 1),
+	}
+}
+
+// Put makes value the latest version of key, evicting its oldest version if all are in use.
+// Keys already in the map are handled according to Config.OnDuplicate, as in Map.Put.
+func (vm *VersionedMap[K, V]) Put(key K, value V) bool {
+	hash := vm.m.hashKey(key)
+
+	if e := vm.m.lookup(hash, key); e != nil {
+		// the ring is shared with the stored entry, so it is only pushed to if the new version is accepted
+		r := (*e).value
+		if vm.m.config.OnDuplicate == Overwrite {
+			r.push(value)
+		}
+		return vm.m.putExisting(e, r) == nil
+	}
+
+	r := versionRing[V]{values: make([]V, vm.versions), head: -1}
+	r.push(value)
+	return vm.m.putNew(hash, key, r) == nil
+}
+
+func (vm *VersionedMap[K, V]) Get(key K) (V, bool) {
+	return vm.GetVersion(key, 0)
+}
+
+// GetVersion returns the value of key as it was back versions before the latest one,
+// which is returned when back is zero. It returns false if that version has been evicted,
+// or was never put.
+func (vm *VersionedMap[K, V]) GetVersion(key K, back int) (V, bool) {
+	r, ok := vm.m.Get(key)
+	if !ok {
+		return zeroValue[V](), false
+	}
+	return r.at(back)
+}
+
+// Versions returns how many versions of key are available.
+func (vm *VersionedMap[K, V]) Versions(key K) int {
+	r, _ := vm.m.Get(key)
+	return r.n
+}
+
+// Delete removes all the versions of key, returning the latest one.
+func (vm *VersionedMap[K, V]) Delete(key K) (V, bool) {
+	r, ok := vm.m.Delete(key)
+	if !ok {
+		return zeroValue[V](), false
+	}
+	return r.at(0)
+}
+
+func (vm *VersionedMap[_, _]) Len() int {
+	return vm.m.Len()
+}
+
+func (vm *VersionedMap[K, V]) Range(fn func(K, V) bool) {
+	vm.m.Range(func(k K, r versionRing[V]) bool {
+		v, _ := r.at(0)
+		return fn(k, v)
+	})
+}
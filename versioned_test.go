@@ -0,0 +1,70 @@
+package hopmap_test
+
+import (
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionedMap(t *testing.T) {
+	m := hopmap.NewVersioned[Key, int](3, hopmap.Config{Size: 16, BucketSize: 8})
+
+	_, ok := m.GetVersion(1, 0)
+	require.False(t, ok)
+	require.Zero(t, m.Versions(1))
+
+	for v := 1; v <= 2; v++ {
+		require.True(t, m.Put(1, v))
+	}
+	require.Equal(t, 2, m.Versions(1))
+	require.Equal(t, 1, m.Len())
+
+	v, _ := m.Get(1)
+	require.Equal(t, 2, v)
+	v, _ = m.GetVersion(1, 1)
+	require.Equal(t, 1, v)
+	_, ok = m.GetVersion(1, 2)
+	require.False(t, ok)
+	_, ok = m.GetVersion(1, -1)
+	require.False(t, ok)
+
+	// the ring evicts the oldest version
+	for v := 3; v <= 5; v++ {
+		require.True(t, m.Put(1, v))
+	}
+	require.Equal(t, 3, m.Versions(1))
+	for back := 0; back < 3; back++ {
+		v, ok := m.GetVersion(1, back)
+		require.True(t, ok)
+		require.Equal(t, 5-back, v)
+	}
+	_, ok = m.GetVersion(1, 3)
+	require.False(t, ok)
+
+	// versions of other keys are independent
+	require.True(t, m.Put(2, 10))
+	require.Equal(t, 1, m.Versions(2))
+
+	v, ok = m.Delete(1)
+	require.True(t, ok)
+	require.Equal(t, 5, v)
+	require.Zero(t, m.Versions(1))
+
+	require.True(t, m.Put(1, 6))
+	_, ok = m.GetVersion(1, 1)
+	require.False(t, ok)
+}
+
+func TestVersionedMapOnDuplicate(t *testing.T) {
+	for _, policy := range []hopmap.DuplicatePolicy{hopmap.KeepExisting, hopmap.FailOnDuplicate} {
+		m := hopmap.NewVersioned[Key, int](1, hopmap.Config{Size: 16, BucketSize: 8, OnDuplicate: policy})
+		require.True(t, m.Put(1, 1))
+
+		// a rejected version leaves the stored ones untouched
+		require.Equal(t, policy == hopmap.KeepExisting, m.Put(1, 2))
+		require.Equal(t, 1, m.Versions(1))
+		v, _ := m.Get(1)
+		require.Equal(t, 1, v)
+	}
+}
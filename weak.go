@@ -0,0 +1,48 @@
+package hopmap
+
+import "weak"
+
+// WeakMap holds its values through weak pointers, so that it never keeps them alive.
+// Entries whose value has been garbage collected are treated as absent and removed lazily.
+type WeakMap[K Hashable[K], V any] struct {
+	m *Map[K, weak.Pointer[V]]
+}
+
+func NewWeak[K Hashable[K], V any](c Config) *WeakMap[K, V] {
+	return &WeakMap[K, V]{
+		m: New[K, weak.Pointer[V]](c),
+	}
+}
+
+func (w *WeakMap[K, V]) Put(key K, value *V) bool {
+	return w.m.Put(key, weak.Make(value))
+}
+
+func (w *WeakMap[K, V]) Get(key K) (*V, bool) {
+	p, ok := w.m.Get(key)
+	if !ok {
+		return nil, false
+	}
+
+	if v := p.Value(); v != nil {
+		return v, true
+	}
+	w.m.Delete(key)
+	return nil, false
+}
+
+func (w *WeakMap[K, V]) Delete(key K) (*V, bool) {
+	p, ok := w.m.Delete(key)
+	if !ok {
+		return nil, false
+	}
+
+	v := p.Value()
+	return v, v != nil
+}
+
+// Len returns the number of entries, including those whose value has been collected
+// but which have not been removed yet.
+func (w *WeakMap[_, _]) Len() int {
+	return w.m.Len()
+}
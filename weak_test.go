@@ -0,0 +1,32 @@
+package hopmap_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/ostafen/hopmap"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeakMap(t *testing.T) {
+	type blob [1024]byte
+
+	m := hopmap.NewWeak[Key, blob](hopmap.DefaultConfig())
+
+	live := new(blob)
+	live[0] = 1
+	require.True(t, m.Put(1, live))
+	require.True(t, m.Put(2, new(blob)))
+
+	runtime.GC()
+
+	v, ok := m.Get(1)
+	require.True(t, ok)
+	require.Equal(t, byte(1), v[0])
+
+	_, ok = m.Get(2)
+	require.False(t, ok)
+	require.Equal(t, 1, m.Len())
+
+	runtime.KeepAlive(live)
+}